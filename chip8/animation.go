@@ -0,0 +1,214 @@
+package chip8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+)
+
+// Animation : Recording of the screen captured every timer tick, encoded as an animated GIF or APNG
+// Frames come from the machine rather than a window, so a run always records the same animation.
+// Runs of identical frames are stored once, shown for as long as they lasted, and frames skipped
+// between captures (e.g. to catch up in real time) are shown as the frame before. The size is set
+// by the first frame, later frames at another resolution are stretched to it.
+type Animation struct {
+	palette    [4]color.Color
+	scale      int
+	timerSpeed int
+	frame      uint64 // machine frame at the last capture
+	frames     []*image.Paletted
+	ticks      []int // timer ticks each frame is shown for
+}
+
+// maxAnimationTicks : Longest a frame is shown for, as APNG delays are 16-bit
+const maxAnimationTicks = 0xFFFF
+
+// NewAnimation : Start an animation with a colour for each palette index, at scale times the resolution
+// The palette is the background, the foreground, the XO-CHIP second plane and both planes.
+func NewAnimation(palette [4]color.Color, scale int) *Animation {
+	if scale < 1 {
+		scale = 1
+	}
+	return &Animation{palette: palette, scale: scale}
+}
+
+// Capture : Add the screen as the next frame, call after each timer tick
+func (animation *Animation) Capture(vm *Machine) {
+	width, height := vm.Resolution()
+	previous := animation.frame
+	animation.frame = vm.Frames()
+	if len(animation.frames) == 0 {
+		animation.timerSpeed = vm.TimerSpeed()
+		frame := image.NewPaletted(image.Rect(0, 0, width*animation.scale, height*animation.scale), animation.palette[:])
+		animation.draw(vm, frame, width, height)
+		animation.frames = append(animation.frames, frame)
+		animation.ticks = append(animation.ticks, 1)
+		return
+	}
+
+	last := len(animation.frames) - 1
+	// frames skipped to catch up, but not jumps from rewinding or loading states
+	if skipped := int(animation.frame - previous - 1); animation.frame > previous && skipped <= animation.timerSpeed {
+		animation.ticks[last] += skipped
+		if animation.ticks[last] > maxAnimationTicks {
+			animation.ticks[last] = maxAnimationTicks
+		}
+	}
+	frame := image.NewPaletted(animation.frames[last].Rect, animation.palette[:])
+	animation.draw(vm, frame, width, height)
+	if bytes.Equal(frame.Pix, animation.frames[last].Pix) && animation.ticks[last] < maxAnimationTicks {
+		animation.ticks[last]++
+		return
+	}
+	animation.frames = append(animation.frames, frame)
+	animation.ticks = append(animation.ticks, 1)
+}
+
+// draw : Draw the screen at width x height to frame, stretched to the frame's size
+func (animation *Animation) draw(vm *Machine, frame *image.Paletted, width int, height int) {
+	size := frame.Rect.Size()
+	for y := 0; y < size.Y; y++ {
+		row := frame.Pix[y*frame.Stride:]
+		for x := 0; x < size.X; x++ {
+			row[x] = vm.Pixel(x*width/size.X, y*height/size.Y)
+		}
+	}
+}
+
+// Ticks : Number of timer ticks captured
+func (animation *Animation) Ticks() int {
+	total := 0
+	for _, ticks := range animation.ticks {
+		total += ticks
+	}
+	return total
+}
+
+// EncodeGIF : Write the animation as a looping GIF
+// GIF delays are in hundredths of a second, so each frame is shown until the hundredth nearest
+// to when it ended, keeping the whole animation in time.
+func (animation *Animation) EncodeGIF(w io.Writer) error {
+	if len(animation.frames) == 0 {
+		return errors.New("no frames captured")
+	}
+	anim := &gif.GIF{Image: animation.frames}
+	var tick, shown int
+	for _, ticks := range animation.ticks {
+		tick += ticks
+		end := (tick*100 + animation.timerSpeed/2) / animation.timerSpeed
+		anim.Delay = append(anim.Delay, end-shown)
+		shown = end
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// EncodeAPNG : Write the animation as a looping animated PNG
+// Each frame is encoded as a PNG, and its image data is moved into the animation's frame chunks.
+func (animation *Animation) EncodeAPNG(w io.Writer) error {
+	if len(animation.frames) == 0 {
+		return errors.New("no frames captured")
+	}
+	if _, err := w.Write([]byte("\x89PNG\r\n\x1a\n")); err != nil {
+		return err
+	}
+	size := animation.frames[0].Rect.Size()
+	var sequence uint32
+	for i, frame := range animation.frames {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, frame); err != nil {
+			return err
+		}
+		chunks, err := pngChunks(buf.Bytes())
+		if err != nil {
+			return err
+		}
+
+		control := make([]byte, 26)
+		binary.BigEndian.PutUint32(control[0:], sequence)
+		binary.BigEndian.PutUint32(control[4:], uint32(size.X))
+		binary.BigEndian.PutUint32(control[8:], uint32(size.Y))
+		binary.BigEndian.PutUint16(control[20:], uint16(animation.ticks[i]))
+		binary.BigEndian.PutUint16(control[22:], uint16(animation.timerSpeed))
+		sequence++
+
+		for _, chunk := range chunks {
+			switch {
+			case chunk.kind == "IEND":
+			case i == 0 && chunk.kind == "IDAT":
+				if err := writePNGChunk(w, "fcTL", control); err != nil {
+					return err
+				}
+				control = nil
+				if err := writePNGChunk(w, "IDAT", chunk.data); err != nil {
+					return err
+				}
+			case i == 0:
+				if err := writePNGChunk(w, chunk.kind, chunk.data); err != nil {
+					return err
+				}
+				if chunk.kind == "IHDR" {
+					frames := make([]byte, 8) // number of frames, then plays (0 loops forever)
+					binary.BigEndian.PutUint32(frames, uint32(len(animation.frames)))
+					if err := writePNGChunk(w, "acTL", frames); err != nil {
+						return err
+					}
+				}
+			case chunk.kind == "IDAT":
+				if control != nil {
+					if err := writePNGChunk(w, "fcTL", control); err != nil {
+						return err
+					}
+					control = nil
+				}
+				data := make([]byte, 4+len(chunk.data))
+				binary.BigEndian.PutUint32(data, sequence)
+				copy(data[4:], chunk.data)
+				sequence++
+				if err := writePNGChunk(w, "fdAT", data); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return writePNGChunk(w, "IEND", nil)
+}
+
+// pngChunk : A chunk of a PNG file
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+// pngChunks : Split an encoded PNG into its chunks
+func pngChunks(encoded []byte) ([]pngChunk, error) {
+	var chunks []pngChunk
+	for rest := encoded[8:]; len(rest) > 0; {
+		if len(rest) < 12 {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		length := int(binary.BigEndian.Uint32(rest))
+		if len(rest) < 12+length {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		chunks = append(chunks, pngChunk{kind: string(rest[4:8]), data: rest[8 : 8+length]})
+		rest = rest[12+length:]
+	}
+	return chunks, nil
+}
+
+// writePNGChunk : Write a PNG chunk with its length and CRC
+func writePNGChunk(w io.Writer, kind string, data []byte) error {
+	chunk := make([]byte, 8+len(data)+4)
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], kind)
+	copy(chunk[8:], data)
+	binary.BigEndian.PutUint32(chunk[8+len(data):], crc32.ChecksumIEEE(chunk[4:8+len(data)]))
+	_, err := w.Write(chunk)
+	return err
+}
@@ -0,0 +1,136 @@
+package chip8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+// blinkROM toggles the digit 0 every 3 timer ticks
+var blinkROM = []byte{
+	0xF0, 0x29, // LD F, V0
+	0xD1, 0x15, // DRW V1, V1, 5
+	0x62, 0x03, // LD V2, 3
+	0xF2, 0x15, // LD DT, V2
+	0xF2, 0x07, // LD V2, DT
+	0x32, 0x00, // SE V2, 0
+	0x12, 0x08, // JP 0x208
+	0x12, 0x02, // JP 0x202
+}
+
+func recordBlink(t *testing.T, ticks int) *Animation {
+	vm := New(Config{})
+	if err := vm.LoadROM(blinkROM); err != nil {
+		t.Fatal(err)
+	}
+	palette := [4]color.Color{color.Black, color.White, color.White, color.White}
+	animation := NewAnimation(palette, 2)
+	for tick := 0; tick < ticks; tick++ {
+		if _, err := vm.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+		animation.Capture(vm)
+	}
+	return animation
+}
+
+func TestAnimationGIF(t *testing.T) {
+	animation := recordBlink(t, 30)
+	if animation.Ticks() != 30 {
+		t.Errorf("Expected ticks incorrect, got: %d", animation.Ticks())
+	}
+	var buf bytes.Buffer
+	if err := animation.EncodeGIF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) < 5 || len(anim.Image) >= 30 {
+		t.Errorf("Expected identical frames to be merged, got: %d frames", len(anim.Image))
+	}
+	total := 0
+	for _, delay := range anim.Delay {
+		total += delay
+	}
+	if total != 50 {
+		t.Errorf("Expected half a second of delays, got: %d", total)
+	}
+	if size := anim.Image[0].Rect.Size(); size.X != 128 || size.Y != 64 {
+		t.Errorf("Expected frame size incorrect, got: %v", size)
+	}
+
+	var again bytes.Buffer
+	if err := recordBlink(t, 30).EncodeGIF(&again); err != nil {
+		t.Fatal(err)
+	}
+	var first bytes.Buffer
+	animation.EncodeGIF(&first)
+	if !bytes.Equal(first.Bytes(), again.Bytes()) {
+		t.Error("Expected identical runs to record identical GIFs")
+	}
+}
+
+func TestAnimationAPNG(t *testing.T) {
+	animation := recordBlink(t, 30)
+	var buf bytes.Buffer
+	if err := animation.EncodeAPNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 128 || size.Y != 64 {
+		t.Errorf("Expected frame size incorrect, got: %v", size)
+	}
+
+	chunks, err := pngChunks(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{}
+	var sequence []uint32
+	for _, chunk := range chunks {
+		counts[chunk.kind]++
+		if chunk.kind == "fcTL" || chunk.kind == "fdAT" {
+			sequence = append(sequence, binary.BigEndian.Uint32(chunk.data))
+		}
+	}
+	if counts["acTL"] != 1 || counts["fcTL"] != len(animation.frames) || counts["fdAT"] < len(animation.frames)-1 {
+		t.Errorf("Expected animation chunks incorrect, got: %v", counts)
+	}
+	for i, n := range sequence {
+		if n != uint32(i) {
+			t.Fatalf("Expected sequence numbers in order, got: %v", sequence)
+		}
+	}
+}
+
+func TestAnimationEmpty(t *testing.T) {
+	animation := NewAnimation([4]color.Color{color.Black, color.White, color.White, color.White}, 1)
+	if err := animation.EncodeGIF(&bytes.Buffer{}); err == nil {
+		t.Error("Expected error encoding an empty animation")
+	}
+}
+
+func TestAnimationSkippedFrames(t *testing.T) {
+	vm := New(Config{})
+	if err := vm.LoadROM(blinkROM); err != nil {
+		t.Fatal(err)
+	}
+	animation := NewAnimation([4]color.Color{color.Black, color.White, color.White, color.White}, 1)
+	for tick := 0; tick < 12; tick++ {
+		vm.RunFrame()
+		if tick%4 == 0 {
+			animation.Capture(vm)
+		}
+	}
+	if animation.Ticks() != 9 {
+		t.Errorf("Expected skipped frames to be shown, got: %d ticks", animation.Ticks())
+	}
+}
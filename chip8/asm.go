@@ -0,0 +1,807 @@
+package chip8
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AsmError : Error in assembly source, at a line of a file
+type AsmError struct {
+	File string
+	Line int
+	Err  string
+}
+
+func (e *AsmError) Error() string { return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Err) }
+
+// asmStatement : Label, constant, instruction or directive on a line, after macro expansion
+type asmStatement struct {
+	line     int
+	label    string
+	constant string // name defined by EQU, the value is the first operand
+	op       string // upper case mnemonic or directive
+	operands []string
+	addr     uint16
+	size     int
+}
+
+// asmMacro : Lines of a macro, with its parameters replaced when it is used
+type asmMacro struct {
+	params []string
+	lines  []string
+}
+
+type assembler struct {
+	file       string
+	statements []*asmStatement
+	symbols    map[string]string // labels (as hex addresses) and constants, as expressions
+	resolving  map[string]bool   // constants being evaluated, to catch circular definitions
+	macros     map[string]*asmMacro
+	expansions int
+}
+
+// Assemble : Assemble source into a ROM loaded at 0x200, returning the ROM and its symbols
+// file names the source in errors and the symbols' source lines.
+//
+// Each line holds an optional label ending in a colon, then an instruction or directive, with
+// comments starting at ";". The mnemonics are the ones Disassemble and DisassembleROM write
+// (CLS, LD V0, 0x10, DRW V0, V1, 5, ...), in any case. Numbers are decimal, hex with 0x or
+// binary with 0b, and expressions combine numbers, labels, constants and $ (the address of the
+// line) with + - * / % & | ^ << >> ~ and parentheses.
+//
+//	NAME EQU expr       constant
+//	DB expr, "text"...  bytes
+//	DW expr...          big-endian words
+//	DS count            count zero bytes
+//	SPRITE "..##.."     sprite row, # or X for set pixels, 8 or 16 wide
+//	JEQ Vx, kk/Vy, addr jump if equal, as SNE and JP
+//	JNE Vx, kk/Vy, addr jump if not equal, as SE and JP
+//	JKP Vx, addr        jump if the key in Vx is pressed, as SKNP and JP
+//	JKNP Vx, addr       jump if the key in Vx isn't pressed, as SKP and JP
+//	MACRO name a, b     start a macro, used as "name 1, V2", up to ENDM
+//
+// In a macro, its parameters are replaced by the arguments it is used with, and \@ by a
+// number unique to each use, for labels.
+func Assemble(file string, source io.Reader) ([]byte, *Symbols, error) {
+	a := &assembler{
+		file:      file,
+		symbols:   make(map[string]string),
+		resolving: make(map[string]bool),
+		macros:    make(map[string]*asmMacro),
+	}
+	var lines []string
+	scanner := bufio.NewScanner(source)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := a.parse(lines); err != nil {
+		return nil, nil, err
+	}
+	if err := a.layout(); err != nil {
+		return nil, nil, err
+	}
+	return a.encode()
+}
+
+func (a *assembler) errorf(line int, format string, args ...interface{}) error {
+	return &AsmError{File: a.file, Line: line, Err: fmt.Sprintf(format, args...)}
+}
+
+// parse : Split lines into statements, collecting and expanding macros
+func (a *assembler) parse(lines []string) error {
+	var macro *asmMacro
+	for i, text := range lines {
+		n := i + 1
+		code := strings.TrimSpace(stripComment(text))
+		fields := strings.Fields(code)
+		switch {
+		case macro != nil && len(fields) > 0 && strings.ToUpper(fields[0]) == "ENDM":
+			macro = nil
+			continue
+		case macro != nil:
+			macro.lines = append(macro.lines, code)
+			continue
+		case len(fields) > 0 && strings.ToUpper(fields[0]) == "MACRO":
+			if len(fields) < 2 {
+				return a.errorf(n, "missing macro name")
+			}
+			name := fields[1]
+			rest := strings.TrimSpace(code[len(fields[0]):])
+			macro = &asmMacro{params: splitOperands(rest[len(name):])}
+			a.macros[strings.ToUpper(name)] = macro
+			continue
+		}
+		if err := a.statement(n, code, 0); err != nil {
+			return err
+		}
+	}
+	if macro != nil {
+		return a.errorf(len(lines), "missing ENDM")
+	}
+	return nil
+}
+
+// statement : Parse a line of code, expanding it if it uses a macro
+func (a *assembler) statement(n int, code string, depth int) error {
+	s := &asmStatement{line: n}
+	if i := strings.Index(code, ":"); i > 0 && isIdentifier(code[:i]) {
+		s.label = code[:i]
+		code = strings.TrimSpace(code[i+1:])
+	}
+	if code != "" {
+		op, rest := code, ""
+		if i := strings.IndexAny(code, " \t"); i >= 0 {
+			op, rest = code[:i], strings.TrimSpace(code[i:])
+		}
+		if fields := strings.Fields(rest); len(fields) > 0 && strings.ToUpper(fields[0]) == "EQU" && isIdentifier(op) {
+			s.constant = op
+			s.operands = []string{strings.TrimSpace(rest[len(fields[0]):])}
+		} else if macro, ok := a.macros[strings.ToUpper(op)]; ok {
+			if s.label != "" {
+				a.statements = append(a.statements, &asmStatement{line: n, label: s.label})
+			}
+			return a.expand(n, macro, splitOperands(rest), depth)
+		} else {
+			s.op = strings.ToUpper(op)
+			s.operands = splitOperands(rest)
+		}
+	}
+	a.statements = append(a.statements, s)
+	return nil
+}
+
+// expand : Add the lines of a macro with its parameters replaced by args
+func (a *assembler) expand(n int, macro *asmMacro, args []string, depth int) error {
+	if depth > 16 {
+		return a.errorf(n, "macros nested too deeply")
+	}
+	if len(args) != len(macro.params) {
+		return a.errorf(n, "macro takes %d arguments, got %d", len(macro.params), len(args))
+	}
+	a.expansions++
+	for _, line := range macro.lines {
+		line = strings.ReplaceAll(line, `\@`, strconv.Itoa(a.expansions))
+		for i, param := range macro.params {
+			line = replaceWord(line, param, args[i])
+		}
+		if err := a.statement(n, line, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// layout : Give each statement its address and size, defining the labels
+func (a *assembler) layout() error {
+	addr := 0x200
+	for _, s := range a.statements {
+		if addr > 0xFFFF {
+			return a.errorf(s.line, "program too large")
+		}
+		s.addr = uint16(addr)
+		if s.label != "" {
+			if _, ok := a.symbols[s.label]; ok {
+				return a.errorf(s.line, "%s already defined", s.label)
+			}
+			a.symbols[s.label] = fmt.Sprintf("0x%X", addr)
+		}
+		if s.constant != "" {
+			if _, ok := a.symbols[s.constant]; ok {
+				return a.errorf(s.line, "%s already defined", s.constant)
+			}
+			a.symbols[s.constant] = "(" + s.operands[0] + ")"
+		}
+		size, err := a.size(s)
+		if err != nil {
+			return a.errorf(s.line, "%v", err)
+		}
+		s.size = size
+		addr += size
+	}
+	return nil
+}
+
+// size : Size in bytes of the statement's instruction or data
+func (a *assembler) size(s *asmStatement) (int, error) {
+	switch s.op {
+	case "":
+		return 0, nil
+	case "DB":
+		size := 0
+		for _, operand := range s.operands {
+			if text, ok := unquote(operand); ok {
+				size += len(text)
+			} else {
+				size++
+			}
+		}
+		return size, nil
+	case "DW":
+		return 2 * len(s.operands), nil
+	case "DS":
+		if len(s.operands) != 1 {
+			return 0, fmt.Errorf("DS takes a count")
+		}
+		count, err := a.eval(s.operands[0], s.addr)
+		if err != nil {
+			return 0, err
+		}
+		if count < 0 || count > 0x10000 {
+			return 0, fmt.Errorf("bad count: %d", count)
+		}
+		return count, nil
+	case "SPRITE":
+		if len(s.operands) != 1 {
+			return 0, fmt.Errorf("SPRITE takes a row")
+		}
+		row, ok := unquote(s.operands[0])
+		if !ok || len(row) == 0 || len(row) > 16 {
+			return 0, fmt.Errorf("bad sprite row: %s, expected up to 16 pixels in quotes", s.operands[0])
+		}
+		return (len(row) + 7) / 8, nil
+	case "JEQ", "JNE", "JKP", "JKNP":
+		return 4, nil
+	case "LD":
+		if len(s.operands) == 2 && strings.EqualFold(s.operands[0], "I") && isLong(s.operands[1]) {
+			return 4, nil
+		}
+	}
+	return 2, nil
+}
+
+func isLong(operand string) bool {
+	fields := strings.Fields(operand)
+	return len(fields) > 1 && strings.EqualFold(fields[0], "long")
+}
+
+// encode : Assemble the statements into bytes, with the symbols for debugging
+func (a *assembler) encode() ([]byte, *Symbols, error) {
+	var rom []byte
+	symbols := &Symbols{}
+	for _, s := range a.statements {
+		if s.label != "" {
+			symbols.Labels = append(symbols.Labels, Label{Name: s.label, Addr: s.addr})
+		}
+		if s.constant != "" {
+			if _, err := a.eval(s.constant, s.addr); err != nil {
+				return nil, nil, a.errorf(s.line, "%v", err)
+			}
+		}
+		if s.op == "" {
+			continue
+		}
+		code, err := a.assemble(s)
+		if err != nil {
+			return nil, nil, a.errorf(s.line, "%v", err)
+		}
+		if len(code) != s.size {
+			return nil, nil, a.errorf(s.line, "size changed between passes")
+		}
+		switch s.op {
+		case "DB", "DW", "DS", "SPRITE":
+		default:
+			symbols.Lines = append(symbols.Lines, SourceLine{Addr: s.addr, File: a.file, Line: s.line})
+		}
+		rom = append(rom, code...)
+	}
+	symbols.sort()
+	return rom, symbols, nil
+}
+
+// asmOperand : Kind of operand, a V register, another register or an expression
+type asmOperand struct {
+	kind  string // "V", "range" (Vx - Vy), "I", "[I]", "DT", "ST", "K", "F", "HF", "B", "R", "long" or "expr"
+	x, y  uint16 // register numbers
+	value string // expression
+}
+
+func parseOperand(operand string) asmOperand {
+	upper := strings.ToUpper(strings.TrimSpace(operand))
+	switch upper {
+	case "I", "[I]", "DT", "ST", "K", "F", "HF", "B", "R":
+		return asmOperand{kind: upper}
+	}
+	if x, ok := vRegister(upper); ok {
+		return asmOperand{kind: "V", x: x}
+	}
+	if parts := strings.Split(upper, "-"); len(parts) == 2 {
+		x, okX := vRegister(strings.TrimSpace(parts[0]))
+		y, okY := vRegister(strings.TrimSpace(parts[1]))
+		if okX && okY {
+			return asmOperand{kind: "range", x: x, y: y}
+		}
+	}
+	if isLong(operand) {
+		return asmOperand{kind: "long", value: strings.TrimSpace(operand)[4:]}
+	}
+	return asmOperand{kind: "expr", value: operand}
+}
+
+func vRegister(s string) (uint16, bool) {
+	if len(s) == 2 && (s[0] == 'V' || s[0] == 'v') {
+		if x, err := strconv.ParseUint(s[1:], 16, 8); err == nil {
+			return uint16(x), true
+		}
+	}
+	return 0, false
+}
+
+// assemble : Bytes of a statement's instruction or data
+func (a *assembler) assemble(s *asmStatement) ([]byte, error) {
+	switch s.op {
+	case "DB", "DW", "DS", "SPRITE":
+		return a.data(s)
+	}
+
+	ops := make([]asmOperand, len(s.operands))
+	var kinds []string
+	for i, operand := range s.operands {
+		ops[i] = parseOperand(operand)
+		kinds = append(kinds, ops[i].kind)
+	}
+	form := s.op + " " + strings.Join(kinds, ",")
+	word := func(opcode uint16) ([]byte, error) { return []byte{byte(opcode >> 8), byte(opcode)}, nil }
+	// value : Operand i as a number of up to bits bits, allowing negative bytes
+	value := func(i int, bits uint) (uint16, error) {
+		v, err := a.eval(ops[i].value, s.addr)
+		if err != nil {
+			return 0, err
+		}
+		if v >= 1<<bits || bits == 8 && v < -128 || bits != 8 && v < 0 {
+			return 0, fmt.Errorf("%s out of range: %d", s.operands[i], v)
+		}
+		return uint16(v) & (1<<bits - 1), nil
+	}
+	jump := func(skip uint16, target int) ([]byte, error) {
+		addr, err := value(target, 12)
+		if err != nil {
+			return nil, err
+		}
+		return []byte{byte(skip >> 8), byte(skip), byte(0x10 | addr>>8), byte(addr)}, nil
+	}
+
+	x, y := uint16(0), uint16(0)
+	if len(ops) > 0 {
+		x = ops[0].x << 8
+	}
+	if len(ops) > 1 {
+		y = ops[1].x << 4
+	}
+
+	switch form {
+	case "CLS ":
+		return word(0x00E0)
+	case "RET ":
+		return word(0x00EE)
+	case "SCR ":
+		return word(0x00FB)
+	case "SCL ":
+		return word(0x00FC)
+	case "EXIT ":
+		return word(0x00FD)
+	case "LOW ":
+		return word(0x00FE)
+	case "HIGH ":
+		return word(0x00FF)
+	case "AUDIO ":
+		return word(0xF002)
+	case "SCD expr", "SCU expr", "PLANE expr":
+		n, err := value(0, 4)
+		if err != nil {
+			return nil, err
+		}
+		switch s.op {
+		case "SCD":
+			return word(0x00C0 | n)
+		case "SCU":
+			return word(0x00D0 | n)
+		}
+		return word(0xF001 | n<<8)
+	case "SYS expr", "JP expr", "CALL expr", "LD I,expr", "JP V,expr":
+		i := len(ops) - 1
+		if s.op == "JP" && i == 1 && ops[0].x != 0 {
+			return nil, fmt.Errorf("JP with a register only takes V0")
+		}
+		nnn, err := value(i, 12)
+		if err != nil {
+			return nil, err
+		}
+		opcode := map[string]uint16{"SYS expr": 0x0000, "JP expr": 0x1000, "CALL expr": 0x2000,
+			"LD I,expr": 0xA000, "JP V,expr": 0xB000}[form]
+		return word(opcode | nnn)
+	case "LD I,long":
+		ops[1].value = strings.TrimSpace(ops[1].value)
+		nnnn, err := value(1, 16)
+		if err != nil {
+			return nil, err
+		}
+		return []byte{0xF0, 0x00, byte(nnnn >> 8), byte(nnnn)}, nil
+	case "SE V,expr", "SNE V,expr", "LD V,expr", "ADD V,expr", "RND V,expr":
+		kk, err := value(1, 8)
+		if err != nil {
+			return nil, err
+		}
+		opcode := map[string]uint16{"SE": 0x3000, "SNE": 0x4000, "LD": 0x6000, "ADD": 0x7000, "RND": 0xC000}[s.op]
+		return word(opcode | x | kk)
+	case "SE V,V":
+		return word(0x5000 | x | y)
+	case "SNE V,V":
+		return word(0x9000 | x | y)
+	case "SAVE range", "LOAD range":
+		opcode := uint16(0x5002)
+		if s.op == "LOAD" {
+			opcode = 0x5003
+		}
+		return word(opcode | ops[0].x<<8 | ops[0].y<<4)
+	case "LD V,V", "OR V,V", "AND V,V", "XOR V,V", "ADD V,V", "SUB V,V", "SHR V,V", "SUBN V,V", "SHL V,V",
+		"SHR V", "SHL V":
+		n := map[string]uint16{"LD": 0, "OR": 1, "AND": 2, "XOR": 3, "ADD": 4, "SUB": 5, "SHR": 6, "SUBN": 7, "SHL": 0xE}[s.op]
+		return word(0x8000 | x | y | n)
+	case "DRW V,V,expr":
+		n, err := value(2, 4)
+		if err != nil {
+			return nil, err
+		}
+		return word(0xD000 | x | y | n)
+	case "SKP V":
+		return word(0xE09E | x)
+	case "SKNP V":
+		return word(0xE0A1 | x)
+	case "LD V,DT":
+		return word(0xF007 | x)
+	case "LD V,K":
+		return word(0xF00A | x)
+	case "LD DT,V", "LD ST,V", "ADD I,V", "LD F,V", "LD HF,V", "LD B,V", "LD [I],V", "LD R,V":
+		kk := map[string]uint16{"LD DT,V": 0x15, "LD ST,V": 0x18, "ADD I,V": 0x1E, "LD F,V": 0x29, "LD HF,V": 0x30,
+			"LD B,V": 0x33, "LD [I],V": 0x55, "LD R,V": 0x75}[form]
+		return word(0xF000 | ops[1].x<<8 | kk)
+	case "PITCH V":
+		return word(0xF03A | x)
+	case "LD V,[I]":
+		return word(0xF065 | x)
+	case "LD V,R":
+		return word(0xF085 | x)
+	case "JEQ V,expr,expr", "JNE V,expr,expr":
+		kk, err := value(1, 8)
+		if err != nil {
+			return nil, err
+		}
+		skip := uint16(0x4000) // SNE, so the jump runs when they're equal
+		if s.op == "JNE" {
+			skip = 0x3000
+		}
+		return jump(skip|x|kk, 2)
+	case "JEQ V,V,expr", "JNE V,V,expr":
+		skip := uint16(0x9000)
+		if s.op == "JNE" {
+			skip = 0x5000
+		}
+		return jump(skip|x|y, 2)
+	case "JKP V,expr":
+		return jump(0xE0A1|x, 1)
+	case "JKNP V,expr":
+		return jump(0xE09E|x, 1)
+	}
+	return nil, fmt.Errorf("unknown instruction: %s %s", s.op, strings.Join(s.operands, ", "))
+}
+
+// data : Bytes of a data directive
+func (a *assembler) data(s *asmStatement) ([]byte, error) {
+	var data []byte
+	switch s.op {
+	case "DB":
+		for _, operand := range s.operands {
+			if text, ok := unquote(operand); ok {
+				data = append(data, text...)
+				continue
+			}
+			v, err := a.eval(operand, s.addr)
+			if err != nil {
+				return nil, err
+			}
+			if v < -128 || v > 0xFF {
+				return nil, fmt.Errorf("byte out of range: %d", v)
+			}
+			data = append(data, byte(v))
+		}
+	case "DW":
+		for _, operand := range s.operands {
+			v, err := a.eval(operand, s.addr)
+			if err != nil {
+				return nil, err
+			}
+			if v < -0x8000 || v > 0xFFFF {
+				return nil, fmt.Errorf("word out of range: %d", v)
+			}
+			data = append(data, byte(v>>8), byte(v))
+		}
+	case "DS":
+		data = make([]byte, s.size)
+	case "SPRITE":
+		row, _ := unquote(s.operands[0])
+		data = make([]byte, s.size)
+		for i, c := range row {
+			switch c {
+			case '#', 'X', 'x', '1':
+				data[i/8] |= 0x80 >> (i % 8)
+			case '.', ' ', '0', '_':
+			default:
+				return nil, fmt.Errorf("bad sprite pixel: %q, expected # or .", c)
+			}
+		}
+	}
+	return data, nil
+}
+
+// eval : Value of an expression, at the address of its line
+func (a *assembler) eval(expr string, addr uint16) (int, error) {
+	tokens, err := tokenizeAsm(expr)
+	if err != nil {
+		return 0, err
+	}
+	p := &asmExprParser{a: a, tokens: tokens, addr: addr}
+	v, err := p.binary(0)
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(tokens) {
+		return 0, fmt.Errorf("unexpected %q in expression: %s", tokens[p.pos], expr)
+	}
+	return v, nil
+}
+
+var asmOperators = []string{"<<", ">>", "+", "-", "*", "/", "%", "&", "|", "^", "~", "(", ")", "$"}
+
+func tokenizeAsm(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+			continue
+		case isAlphanumeric(c) || c == '_' || c == '.':
+			j := i
+			for j < len(s) && (isAlphanumeric(s[j]) || s[j] == '_' || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+			continue
+		case c == '\'' && i+2 < len(s) && s[i+2] == '\'':
+			tokens = append(tokens, strconv.Itoa(int(s[i+1])))
+			i += 3
+			continue
+		}
+		matched := false
+		for _, op := range asmOperators {
+			if strings.HasPrefix(s[i:], op) {
+				tokens = append(tokens, op)
+				i += len(op)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unexpected %q in expression: %s", c, s)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("missing value")
+	}
+	return tokens, nil
+}
+
+type asmExprParser struct {
+	a      *assembler
+	tokens []string
+	pos    int
+	addr   uint16
+}
+
+// asmPrecedence : Binary operators from lowest to highest precedence
+var asmPrecedence = [][]string{{"|"}, {"^"}, {"&"}, {"<<", ">>"}, {"+", "-"}, {"*", "/", "%"}}
+
+func (p *asmExprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *asmExprParser) binary(level int) (int, error) {
+	if level == len(asmPrecedence) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		found := false
+		for _, o := range asmPrecedence[level] {
+			found = found || o == op
+		}
+		if !found {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "|":
+			left |= right
+		case "^":
+			left ^= right
+		case "&":
+			left &= right
+		case "<<":
+			left <<= uint(right)
+		case ">>":
+			left >>= uint(right)
+		case "+":
+			left += right
+		case "-":
+			left -= right
+		case "*":
+			left *= right
+		case "/", "%":
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			if op == "/" {
+				left /= right
+			} else {
+				left %= right
+			}
+		}
+	}
+}
+
+func (p *asmExprParser) unary() (int, error) {
+	switch p.peek() {
+	case "-", "~", "+":
+		op := p.peek()
+		p.pos++
+		v, err := p.unary()
+		switch op {
+		case "-":
+			v = -v
+		case "~":
+			v = ^v
+		}
+		return v, err
+	}
+	return p.primary()
+}
+
+func (p *asmExprParser) primary() (int, error) {
+	token := p.peek()
+	if token == "" {
+		return 0, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	switch {
+	case token == "(":
+		v, err := p.binary(0)
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ")" {
+			return 0, fmt.Errorf("missing \")\" in expression")
+		}
+		p.pos++
+		return v, nil
+	case token == "$":
+		return int(p.addr), nil
+	case token[0] >= '0' && token[0] <= '9':
+		v, err := strconv.ParseInt(token, 0, 32)
+		if err != nil {
+			return 0, fmt.Errorf("bad number: %s", token)
+		}
+		return int(v), nil
+	case isIdentifier(token):
+		return p.a.symbol(token)
+	}
+	return 0, fmt.Errorf("unexpected %q in expression", token)
+}
+
+// symbol : Value of a label or constant, constants are evaluated where they are used
+func (a *assembler) symbol(name string) (int, error) {
+	expr, ok := a.symbols[name]
+	if !ok {
+		return 0, fmt.Errorf("undefined: %s", name)
+	}
+	if a.resolving[name] {
+		return 0, fmt.Errorf("circular definition of %s", name)
+	}
+	a.resolving[name] = true
+	defer delete(a.resolving, name)
+	return a.eval(expr, 0)
+}
+
+func isIdentifier(s string) bool {
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isAlphanumeric(s[i]) && s[i] != '_' && s[i] != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// stripComment : Line without its comment, ignoring ; in quotes
+func stripComment(line string) string {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// splitOperands : Comma separated operands, ignoring commas in quotes
+func splitOperands(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	var operands []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				operands = append(operands, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(operands, strings.TrimSpace(s[start:]))
+}
+
+func unquote(operand string) (string, bool) {
+	if len(operand) >= 2 && operand[0] == '"' && operand[len(operand)-1] == '"' {
+		return operand[1 : len(operand)-1], true
+	}
+	return "", false
+}
+
+// replaceWord : Replace whole words old in s with new
+func replaceWord(s, old, new string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], old) &&
+			(i == 0 || !isWordByte(s[i-1])) &&
+			(i+len(old) == len(s) || !isWordByte(s[i+len(old)])) {
+			sb.WriteString(new)
+			i += len(old)
+			continue
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String()
+}
+
+func isWordByte(c byte) bool { return isAlphanumeric(c) || c == '_' || c == '.' }
@@ -0,0 +1,145 @@
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func assembleString(t *testing.T, source string) ([]byte, *Symbols) {
+	t.Helper()
+	rom, symbols, err := Assemble("test.asm", strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rom, symbols
+}
+
+func TestAssemble(t *testing.T) {
+	rom, symbols := assembleString(t, `
+; draw a box, then wait for a key
+SIZE EQU 5
+X    EQU SIZE * 2 + 1
+
+start:
+    CLS
+    LD I, box           ; the sprite
+    LD V0, X
+    LD V1, -1
+    ADD V1, 0x01
+    DRW V0, V1, SIZE
+    LD V2, K
+    JEQ V2, 0xA, start
+    JNE V2, V3, $
+    SAVE V1 - V3
+    LD I, long end
+    JP V0, table
+table:
+    JP start
+box:
+    SPRITE "#####"
+    SPRITE "#...#"
+    DB 0b10001000, 0x88, "AB"
+    DW 0x1234
+    DS 2
+end:
+`)
+	want := []byte{
+		0x00, 0xE0,
+		0xA2, 0x20,
+		0x60, 0x0B,
+		0x61, 0xFF,
+		0x71, 0x01,
+		0xD0, 0x15,
+		0xF2, 0x0A,
+		0x42, 0x0A, 0x12, 0x00,
+		0x52, 0x30, 0x12, 0x12,
+		0x51, 0x32,
+		0xF0, 0x00, 0x02, 0x2A,
+		0xB2, 0x1E,
+		0x12, 0x00,
+		0xF8, 0x88, 0x88, 0x88, 'A', 'B', 0x12, 0x34, 0x00, 0x00,
+	}
+	if !bytes.Equal(rom, want) {
+		t.Errorf("Expected ROM incorrect, got:\n% x\nwant:\n% x", rom, want)
+	}
+	if addr, ok := symbols.LabelAddr("box"); !ok || addr != 0x220 {
+		t.Errorf("Expected box at 0x220, got: 0x%x", addr)
+	}
+	if line, ok := symbols.Line(0x202); !ok || line.File != "test.asm" || line.Line != 8 {
+		t.Errorf("Expected LD I on line 8, got: %v", line)
+	}
+	if _, ok := symbols.Line(0x220); ok {
+		t.Errorf("Expected no source line for data")
+	}
+}
+
+func TestAssembleMacro(t *testing.T) {
+	rom, _ := assembleString(t, `
+MACRO wait_key reg
+loop\@:
+    SKP reg
+    JP loop\@
+ENDM
+MACRO pair a, b
+    LD VA, a
+    wait_key b
+ENDM
+    wait_key V1
+    pair 3, V2
+`)
+	want := []byte{0xE1, 0x9E, 0x12, 0x00, 0x6A, 0x03, 0xE2, 0x9E, 0x12, 0x06}
+	if !bytes.Equal(rom, want) {
+		t.Errorf("Expected macro expansion incorrect, got: % x, want: % x", rom, want)
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	cases := []struct {
+		source string
+		line   int
+	}{
+		{"CLS\nLD V0, 256", 2},
+		{"JP nowhere", 1},
+		{"a:\na:", 2},
+		{"FOO V1", 1},
+		{"A EQU B\nB EQU A\nLD V0, A", 1},
+		{"MACRO m x\nCLS", 2},
+		{"DRW V0, V1, 16", 1},
+		{"JP V1, 0x300", 1},
+		{"SPRITE \"#?\"", 1},
+	}
+	for _, c := range cases {
+		_, _, err := Assemble("bad.asm", strings.NewReader(c.source))
+		var asmErr *AsmError
+		if !errors.As(err, &asmErr) || asmErr.Line != c.line {
+			t.Errorf("Expected an error on line %d for %q, got: %v", c.line, c.source, err)
+		}
+	}
+}
+
+// TestDisassembleRoundTrip : Every ROM disassembles to source that assembles to the same bytes
+func TestDisassembleRoundTrip(t *testing.T) {
+	files, _ := filepath.Glob("../roms/*/*.ch8")
+	if len(files) == 0 {
+		t.Skip("no ROMs found")
+	}
+	for _, file := range files {
+		rom, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		source := DisassembleROM(rom)
+		again, _, err := Assemble(file, strings.NewReader(source))
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if !bytes.Equal(again, rom) {
+			t.Errorf("%s: round trip changed the ROM", file)
+		}
+	}
+}
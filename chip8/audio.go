@@ -0,0 +1,124 @@
+package chip8
+
+import "math"
+
+// Waveforms for Tone
+const (
+	WaveSquare   = "square"
+	WaveTriangle = "triangle"
+	WaveSawtooth = "sawtooth"
+	WaveSine     = "sine"
+)
+
+// toneFade : Seconds the tone takes to fade in or out, so starting and stopping doesn't click
+const toneFade = 0.005
+
+// Tone : Generator of a continuous tone for the sound timer, as signed 16-bit mono samples
+// The phase carries on from one call of Samples to the next, and the tone fades in and out
+// when it starts, stops or is muted, so an Audio backend can feed it to a sound device a tick
+// at a time without clicks.
+type Tone struct {
+	SampleRate int     // samples per second (default: 44100)
+	Frequency  float64 // Hz (default: 440)
+	Waveform   string  // WaveSquare (default), WaveTriangle, WaveSawtooth or WaveSine
+	Volume     float64 // 0 to 1
+	Muted      bool
+	phase      float64 // position in the current cycle, 0 to 1
+	level      float64 // fade in and out, 0 to 1
+}
+
+// ValidWaveform : Whether a waveform name is one of the Wave constants
+func ValidWaveform(waveform string) bool {
+	switch waveform {
+	case WaveSquare, WaveTriangle, WaveSawtooth, WaveSine:
+		return true
+	}
+	return false
+}
+
+// Rate : Samples per second, with the default for 0
+func (tone *Tone) Rate() int {
+	if tone.SampleRate == 0 {
+		return 44100
+	}
+	return tone.SampleRate
+}
+
+// Samples : Fill samples with the tone if playing is true, otherwise with silence after fading out
+func (tone *Tone) Samples(samples []int16, playing bool) {
+	frequency := tone.Frequency
+	if frequency == 0 {
+		frequency = 440
+	}
+	tone.fill(samples, playing, frequency, tone.wave)
+}
+
+// PatternSamples : Fill samples with an XO-CHIP audio pattern played at rate bits per second if
+// playing is true, otherwise with silence after fading out
+// The 128 bits loop from the most significant bit of the first byte, a 1 bit high and a 0 bit low.
+func (tone *Tone) PatternSamples(samples []int16, playing bool, pattern [16]uint8, rate float64) {
+	tone.fill(samples, playing, rate/128, func() float64 {
+		bit := int(tone.phase * 128)
+		if pattern[bit/8]&(0x80>>(bit%8)) != 0 {
+			return 1
+		}
+		return -1
+	})
+}
+
+// fill : Fill samples with a wave of frequency cycles per second, fading in and out
+func (tone *Tone) fill(samples []int16, playing bool, frequency float64, wave func() float64) {
+	rate := float64(tone.Rate())
+	target := 0.0
+	if playing && !tone.Muted {
+		target = 1
+	}
+	fade := 1 / (toneFade * rate)
+	for i := range samples {
+		switch {
+		case tone.level < target:
+			tone.level = math.Min(target, tone.level+fade)
+		case tone.level > target:
+			tone.level = math.Max(target, tone.level-fade)
+		}
+		if tone.level == 0 {
+			// restart the cycle, so the next beep starts the same way
+			tone.phase = 0
+			samples[i] = 0
+			continue
+		}
+		samples[i] = int16(wave() * tone.level * tone.Volume * math.MaxInt16)
+		tone.phase += frequency / rate
+		tone.phase -= math.Floor(tone.phase)
+	}
+}
+
+// wave : Value of the waveform at the current phase, -1 to 1
+func (tone *Tone) wave() float64 {
+	switch tone.Waveform {
+	case WaveTriangle:
+		return 1 - 4*math.Abs(tone.phase-0.5)
+	case WaveSawtooth:
+		return 2*tone.phase - 1
+	case WaveSine:
+		return math.Sin(2 * math.Pi * tone.phase)
+	}
+	if tone.phase < 0.5 {
+		return 1
+	}
+	return -1
+}
+
+// NullAudio : Audio that only counts timer ticks, for headless runs and tests
+type NullAudio struct {
+	Ticks      int // timer ticks
+	SoundTicks int // timer ticks with the sound timer active
+}
+
+// SoundTick : Count the tick
+func (audio *NullAudio) SoundTick(playing bool) {
+	audio.Ticks++
+	if playing {
+		audio.SoundTicks++
+	}
+}
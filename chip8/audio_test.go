@@ -0,0 +1,60 @@
+package chip8
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToneFade(t *testing.T) {
+	tone := Tone{SampleRate: 44100, Frequency: 440, Waveform: WaveSine, Volume: 0.5}
+	peak := 0.5 * math.MaxInt16
+	// the largest step between samples of a sine at full volume, plus the fade
+	maxStep := peak*(2*math.Pi*440/44100) + peak/(toneFade*44100) + 1
+
+	var samples []int16
+	for tick, playing := range []bool{false, true, true, true, false, false} {
+		block := make([]int16, 735)
+		tone.Samples(block, playing)
+		if tick == 0 && block[len(block)-1] != 0 {
+			t.Errorf("Expected silence before playing, got: %d", block[len(block)-1])
+		}
+		samples = append(samples, block...)
+	}
+
+	loudest := 0.0
+	for i := 1; i < len(samples); i++ {
+		if step := math.Abs(float64(samples[i]) - float64(samples[i-1])); step > maxStep {
+			t.Fatalf("Expected no click, got a step of %.0f at sample %d", step, i)
+		}
+		loudest = math.Max(loudest, math.Abs(float64(samples[i])))
+	}
+	if loudest < peak*0.99 || loudest > peak {
+		t.Errorf("Expected peak incorrect, got: %.0f, want: %.0f", loudest, peak)
+	}
+	if last := samples[len(samples)-1]; last != 0 {
+		t.Errorf("Expected silence after stopping, got: %d", last)
+	}
+}
+
+func TestToneMuted(t *testing.T) {
+	tone := Tone{Volume: 1, Muted: true}
+	samples := make([]int16, 1000)
+	tone.Samples(samples, true)
+	for i, sample := range samples {
+		if sample != 0 {
+			t.Fatalf("Expected muted tone to be silent, got: %d at sample %d", sample, i)
+		}
+	}
+}
+
+func TestNullAudio(t *testing.T) {
+	audio := &NullAudio{}
+	vm := New(Config{Audio: audio})
+	vm.SetSoundTimer(3)
+	for tick := 0; tick < 5; tick++ {
+		vm.TickTimers()
+	}
+	if audio.Ticks != 5 || audio.SoundTicks != 3 {
+		t.Errorf("Expected ticks incorrect, got: %d, %d sounding", audio.Ticks, audio.SoundTicks)
+	}
+}
@@ -0,0 +1,362 @@
+package chip8
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Debugger : Breakpoints and stepping for a Machine
+// Once attached with NewDebugger, Step returns a *Break instead of running an instruction that
+// hits a breakpoint, and the debugger stays paused until Continue (or one of the step commands).
+type Debugger struct {
+	vm          *Machine
+	breakpoints []*Breakpoint
+	nextID      int
+	paused      bool
+	interrupt   bool        // pause before the next instruction
+	skip        bool        // run the next instruction without checking breakpoints, when resuming
+	until       *Breakpoint // temporary breakpoint of StepOver, StepOut and RunTo
+	watchHit    *Break      // watchpoint hit by the running instruction
+	stop        *Break      // why the debugger last paused
+}
+
+// Breakpoint : Condition checked before each instruction, or a watchpoint on memory accesses
+type Breakpoint struct {
+	ID   int
+	Spec string // as given to AddBreakpoint
+	Hits int
+
+	match      func(vm *Machine) bool
+	edge       bool // only break when the condition becomes true, for expressions
+	last       bool // value of the condition before the last instruction
+	watchRead  bool // watchpoint on reads of start-end
+	watchWrite bool // watchpoint on writes to start-end
+	start, end uint16
+}
+
+// Break : Returned by Step when the debugger pauses, before the instruction at PC runs
+type Break struct {
+	PC         uint16
+	Breakpoint int           // ID of the breakpoint that was hit, 0 for interrupts and steps
+	Reason     string        // breakpoint spec, "interrupt", "step over", "step out" or "run to 0x..."
+	Access     *MemoryAccess // access that hit a watchpoint, after the instruction making it has run
+}
+
+// MemoryAccess : Memory read or written by an instruction
+type MemoryAccess struct {
+	PC    uint16 // address of the instruction
+	Addr  uint16
+	Size  int
+	Write bool
+}
+
+func (brk *Break) Error() string {
+	if brk.Access != nil {
+		access := "read from"
+		if brk.Access.Write {
+			access = "written to"
+		}
+		return fmt.Sprintf("watchpoint %d (%s) at PC: 0x%x, %d bytes %s 0x%x by 0x%x",
+			brk.Breakpoint, brk.Reason, brk.PC, brk.Access.Size, access, brk.Access.Addr, brk.Access.PC)
+	}
+	if brk.Breakpoint != 0 {
+		return fmt.Sprintf("breakpoint %d (%s) at PC: 0x%x", brk.Breakpoint, brk.Reason, brk.PC)
+	}
+	return fmt.Sprintf("%s at PC: 0x%x", brk.Reason, brk.PC)
+}
+
+// NewDebugger : Attach a debugger to vm, which keeps running until a breakpoint or Interrupt
+func NewDebugger(vm *Machine) *Debugger {
+	debugger := &Debugger{vm: vm, nextID: 1}
+	vm.debugger = debugger
+	return debugger
+}
+
+// AddBreakpoint : Add a breakpoint, spec is one of:
+//
+//	0x2A4            PC reaches the address (hex, the 0x is optional)
+//	op:Dxyn          opcode at PC matches, hex digits must match and any other character matches anything
+//	w:300-30F        watchpoint on instructions writing to the address range, r: for reads and rw: for both
+//	i:300-30F        I points into the address range
+//	V3 == 0x10 && DT == 0
+//	                 expression becomes true, see ParseExpression
+//
+// Watchpoints use the machine's memory hook, replacing any set with SetMemoryHook.
+func (debugger *Debugger) AddBreakpoint(spec string) (Breakpoint, error) {
+	bp, err := parseBreakpoint(spec)
+	if err != nil {
+		return Breakpoint{}, err
+	}
+	bp.ID = debugger.nextID
+	debugger.nextID++
+	bp.last = bp.match(debugger.vm)
+	debugger.breakpoints = append(debugger.breakpoints, bp)
+	debugger.updateHook()
+	return *bp, nil
+}
+
+// RemoveBreakpoint : Remove the breakpoint with the ID, false if there is none
+func (debugger *Debugger) RemoveBreakpoint(id int) bool {
+	for i, bp := range debugger.breakpoints {
+		if bp.ID == id {
+			debugger.breakpoints = append(debugger.breakpoints[:i], debugger.breakpoints[i+1:]...)
+			debugger.updateHook()
+			return true
+		}
+	}
+	return false
+}
+
+// updateHook : Watch memory accesses only while there are watchpoints, so they cost nothing otherwise
+func (debugger *Debugger) updateHook() {
+	for _, bp := range debugger.breakpoints {
+		if bp.watchRead || bp.watchWrite {
+			debugger.vm.SetMemoryHook(debugger.memoryAccess)
+			return
+		}
+	}
+	debugger.vm.SetMemoryHook(nil)
+}
+
+// memoryAccess : Memory hook recording the first watchpoint hit by the running instruction
+func (debugger *Debugger) memoryAccess(addr uint16, size int, write bool) {
+	if debugger.watchHit != nil {
+		return
+	}
+	last := int(addr) + size - 1
+	for _, bp := range debugger.breakpoints {
+		if (write && bp.watchWrite || !write && bp.watchRead) && int(addr) <= int(bp.end) && last >= int(bp.start) {
+			debugger.watchHit = &Break{Breakpoint: bp.ID, Reason: bp.Spec,
+				Access: &MemoryAccess{PC: debugger.vm.pc, Addr: addr, Size: size, Write: write}}
+			return
+		}
+	}
+}
+
+// watched : Called by Step after each instruction, returns the break if it hit a watchpoint
+func (debugger *Debugger) watched() *Break {
+	brk := debugger.watchHit
+	if brk == nil {
+		return nil
+	}
+	debugger.watchHit = nil
+	for _, bp := range debugger.breakpoints {
+		if bp.ID == brk.Breakpoint {
+			bp.Hits++
+		}
+	}
+	brk.PC = debugger.vm.pc
+	return debugger.pause(brk)
+}
+
+// Breakpoints : Breakpoints in the order they were added
+func (debugger *Debugger) Breakpoints() []Breakpoint {
+	breakpoints := make([]Breakpoint, len(debugger.breakpoints))
+	for i, bp := range debugger.breakpoints {
+		breakpoints[i] = *bp
+	}
+	return breakpoints
+}
+
+// Paused : Whether the machine is stopped in the debugger
+func (debugger *Debugger) Paused() bool { return debugger.paused }
+
+// Stopped : Break that last paused the machine, nil when it paused on a fault with the break policy
+func (debugger *Debugger) Stopped() *Break { return debugger.stop }
+
+// pause : Stop in the debugger for brk
+func (debugger *Debugger) pause(brk *Break) *Break {
+	debugger.until = nil
+	debugger.paused = true
+	debugger.stop = brk
+	return brk
+}
+
+// Interrupt : Pause before the next instruction
+func (debugger *Debugger) Interrupt() {
+	if !debugger.paused {
+		debugger.interrupt = true
+	}
+}
+
+// Continue : Resume running, from the instruction at PC even if it has a breakpoint
+func (debugger *Debugger) Continue() {
+	debugger.paused = false
+	debugger.interrupt = false
+	debugger.skip = true
+}
+
+// Step : Run a single instruction while paused, rendering the screen if it changed
+// Timers tick as the instructions of each frame are stepped through, and a wait for the
+// DisplayWait quirk's timer tick is stepped through until the next instruction runs.
+func (debugger *Debugger) Step() (bool, error) {
+	debugger.Continue()
+	cycles := debugger.vm.cycles
+	running, err := debugger.vm.stepInFrame()
+	for running && err == nil && debugger.vm.cycles == cycles {
+		running, err = debugger.vm.stepInFrame()
+	}
+	if !debugger.paused {
+		debugger.pause(&Break{PC: debugger.vm.pc, Reason: "step"})
+	}
+	if debugger.vm.dirty {
+		debugger.vm.Render()
+	}
+	return running, err
+}
+
+// StepOver : Run a CALL until its subroutine returns, other instructions are stepped as by Step
+// The machine runs at its normal speed until then, unless a breakpoint is hit first.
+func (debugger *Debugger) StepOver() (bool, error) {
+	vm := debugger.vm
+	if int(vm.pc)+1 >= vm.memSize || vm.memory[vm.pc]&0xF0 != 0x20 {
+		return debugger.Step()
+	}
+	ret, sp := vm.pc+2, vm.sp
+	debugger.resumeUntil("step over", func(vm *Machine) bool {
+		return vm.pc == ret && vm.sp == sp
+	})
+	return true, nil
+}
+
+// StepOut : Run until the current subroutine returns
+func (debugger *Debugger) StepOut() {
+	sp := debugger.vm.sp
+	debugger.resumeUntil("step out", func(vm *Machine) bool {
+		return vm.sp < sp
+	})
+}
+
+// RunTo : Run until PC reaches addr
+func (debugger *Debugger) RunTo(addr uint16) {
+	debugger.resumeUntil(fmt.Sprintf("run to 0x%x", addr), func(vm *Machine) bool {
+		return vm.pc == addr
+	})
+}
+
+func (debugger *Debugger) resumeUntil(reason string, match func(vm *Machine) bool) {
+	debugger.until = &Breakpoint{Spec: reason, match: match}
+	debugger.Continue()
+}
+
+// check : Called by Step before each instruction, returns the break if the machine should pause
+func (debugger *Debugger) check() *Break {
+	vm := debugger.vm
+	if debugger.paused {
+		return &Break{PC: vm.pc, Reason: "paused"}
+	}
+	skip := debugger.skip
+	debugger.skip = false
+	debugger.watchHit = nil
+
+	var hit *Breakpoint
+	for _, bp := range debugger.breakpoints {
+		matched := bp.match(vm)
+		if matched && !(bp.edge && bp.last) && !skip && hit == nil {
+			hit = bp
+		}
+		bp.last = matched
+	}
+
+	switch {
+	case debugger.interrupt:
+		debugger.interrupt = false
+		return debugger.pause(&Break{PC: vm.pc, Reason: "interrupt"})
+	case hit != nil:
+		hit.Hits++
+		return debugger.pause(&Break{PC: vm.pc, Breakpoint: hit.ID, Reason: hit.Spec})
+	case debugger.until != nil && !skip && debugger.until.match(vm):
+		return debugger.pause(&Break{PC: vm.pc, Reason: debugger.until.Spec})
+	}
+	return nil
+}
+
+func parseBreakpoint(spec string) (*Breakpoint, error) {
+	spec = strings.TrimSpace(spec)
+	bp := &Breakpoint{Spec: spec}
+
+	if strings.HasPrefix(strings.ToLower(spec), "op:") {
+		pattern := spec[3:]
+		if len(pattern) != 4 {
+			return nil, fmt.Errorf("bad opcode pattern: %s, expected 4 characters e.g. Dxyn", pattern)
+		}
+		var value, mask uint16
+		for _, c := range pattern {
+			value, mask = value<<4, mask<<4
+			if digit, err := strconv.ParseUint(string(c), 16, 8); err == nil {
+				value |= uint16(digit)
+				mask |= 0xF
+			}
+		}
+		bp.match = func(vm *Machine) bool {
+			if int(vm.pc)+1 >= vm.memSize {
+				return false
+			}
+			opcode := uint16(vm.memory[vm.pc])<<8 | uint16(vm.memory[vm.pc+1])
+			return opcode&mask == value
+		}
+		return bp, nil
+	}
+
+	if i := strings.Index(spec, ":"); i > 0 {
+		start, end, err := parseRange(spec[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(spec[:i]) {
+		case "r":
+			bp.watchRead = true
+		case "w":
+			bp.watchWrite = true
+		case "rw":
+			bp.watchRead, bp.watchWrite = true, true
+		case "i":
+			bp.edge = true
+			bp.match = func(vm *Machine) bool { return vm.i >= start && vm.i <= end }
+			return bp, nil
+		default:
+			return nil, fmt.Errorf("bad breakpoint: %s, expected op:, r:, w:, rw: or i:", spec)
+		}
+		bp.start, bp.end = start, end
+		bp.match = func(vm *Machine) bool { return false }
+		return bp, nil
+	}
+
+	if addr, err := parseAddress(spec); err == nil {
+		pc := addr
+		bp.match = func(vm *Machine) bool { return vm.pc == pc }
+		return bp, nil
+	}
+
+	expr, err := ParseExpression(spec)
+	if err != nil {
+		return nil, fmt.Errorf("bad breakpoint: %s, %v", spec, err)
+	}
+	bp.edge = true
+	bp.match = func(vm *Machine) bool { return expr(vm) != 0 }
+	return bp, nil
+}
+
+// parseAddress : Address in hex, with or without the 0x prefix as shown in disassembly
+func parseAddress(s string) (uint16, error) {
+	addr, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 16)
+	return uint16(addr), err
+}
+
+// parseRange : Address range "300-30F" (inclusive) or single address "300"
+func parseRange(s string) (uint16, uint16, error) {
+	startText, endText := s, s
+	if i := strings.Index(s, "-"); i >= 0 {
+		startText, endText = s[:i], s[i+1:]
+	}
+	start, err := parseAddress(strings.TrimSpace(startText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad address range: %s", s)
+	}
+	end, err := parseAddress(strings.TrimSpace(endText))
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("bad address range: %s", s)
+	}
+	return start, end, nil
+}
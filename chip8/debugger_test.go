@@ -0,0 +1,237 @@
+package chip8
+
+import (
+	"errors"
+	"testing"
+)
+
+// debugROM : Calls a subroutine that counts V0 up, with a loop
+var debugROM = []byte{
+	0x60, 0x00, // 0x200 LD V0, 0
+	0x22, 0x08, // 0x202 CALL 0x208
+	0x61, 0x01, // 0x204 LD V1, 1
+	0x12, 0x02, // 0x206 JP 0x202
+	0x70, 0x01, // 0x208 ADD V0, 1
+	0x00, 0xEE, // 0x20A RET
+}
+
+func returnDebugVM(t *testing.T) (*Machine, *Debugger) {
+	vm := New(Config{CyclesPerFrame: 10})
+	if err := vm.LoadROM(debugROM); err != nil {
+		t.Fatal(err)
+	}
+	return vm, NewDebugger(vm)
+}
+
+// runToBreak : Run frames until the debugger pauses
+func runToBreak(t *testing.T, vm *Machine) *Break {
+	for f := 0; f < 100; f++ {
+		_, err := vm.SkipFrame()
+		var brk *Break
+		if errors.As(err, &brk) {
+			return brk
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Fatalf("Expected break, still running at PC: 0x%x", vm.pc)
+	return nil
+}
+
+func TestBreakpointPC(t *testing.T) {
+	vm, debugger := returnDebugVM(t)
+	bp, err := debugger.AddBreakpoint("0x208")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	brk := runToBreak(t, vm)
+	if brk.Breakpoint != bp.ID || vm.pc != 0x208 || vm.v[0] != 0 || !debugger.Paused() {
+		t.Errorf("Expected break incorrect, got: %v, V0: %d", brk, vm.v[0])
+	}
+	// paused machines don't run
+	if _, err := vm.Step(); err == nil || vm.pc != 0x208 {
+		t.Errorf("Expected paused machine not to step, got PC: 0x%x", vm.pc)
+	}
+
+	debugger.Continue()
+	runToBreak(t, vm)
+	if vm.pc != 0x208 || vm.v[0] != 1 || debugger.Breakpoints()[0].Hits != 2 {
+		t.Errorf("Expected second break incorrect, got PC: 0x%x V0: %d hits: %d", vm.pc, vm.v[0], debugger.Breakpoints()[0].Hits)
+	}
+
+	if !debugger.RemoveBreakpoint(bp.ID) || debugger.RemoveBreakpoint(bp.ID) {
+		t.Errorf("Expected breakpoint removal incorrect")
+	}
+}
+
+func TestBreakpointOpcode(t *testing.T) {
+	vm, debugger := returnDebugVM(t)
+	if _, err := debugger.AddBreakpoint("op:7x01"); err != nil {
+		t.Fatal(err)
+	}
+	runToBreak(t, vm)
+	if vm.pc != 0x208 {
+		t.Errorf("Expected opcode break incorrect, got PC: 0x%x", vm.pc)
+	}
+}
+
+func TestBreakpointCondition(t *testing.T) {
+	vm, debugger := returnDebugVM(t)
+	if _, err := debugger.AddBreakpoint("V0 >= 3"); err != nil {
+		t.Fatal(err)
+	}
+	runToBreak(t, vm)
+	if vm.v[0] != 3 || vm.pc != 0x20A {
+		t.Errorf("Expected condition break incorrect, got PC: 0x%x V0: %d", vm.pc, vm.v[0])
+	}
+	// conditions break when they become true, not while they stay true
+	debugger.Continue()
+	for c := 0; c < 20; c++ {
+		if _, err := vm.Step(); err != nil {
+			t.Fatalf("Expected no break while the condition stays true, got: %v", err)
+		}
+	}
+
+	for _, spec := range []string{"V0 = 3", "VG==1", "op:12", "xyz"} {
+		if _, err := debugger.AddBreakpoint(spec); err == nil {
+			t.Errorf("Expected an error for breakpoint %q", spec)
+		}
+	}
+}
+
+func TestDebuggerStep(t *testing.T) {
+	vm, debugger := returnDebugVM(t)
+	debugger.Interrupt()
+	if brk := runToBreak(t, vm); brk.Reason != "interrupt" || vm.pc != 0x200 {
+		t.Errorf("Expected interrupt incorrect, got: %v", brk)
+	}
+
+	debugger.Step()
+	debugger.Step()
+	if vm.pc != 0x208 || vm.sp != 1 || !debugger.Paused() {
+		t.Errorf("Expected step into CALL incorrect, got PC: 0x%x SP: %d", vm.pc, vm.sp)
+	}
+
+	debugger.StepOut()
+	if brk := runToBreak(t, vm); brk.Reason != "step out" || vm.pc != 0x204 || vm.sp != 0 {
+		t.Errorf("Expected step out incorrect, got: %v, SP: %d", brk, vm.sp)
+	}
+
+	debugger.Step()
+	debugger.Step()
+	debugger.StepOver()
+	if brk := runToBreak(t, vm); brk.Reason != "step over" || vm.pc != 0x204 || vm.v[0] != 2 {
+		t.Errorf("Expected step over incorrect, got: %v, V0: %d", brk, vm.v[0])
+	}
+
+	debugger.RunTo(0x20A)
+	if brk := runToBreak(t, vm); vm.pc != 0x20A {
+		t.Errorf("Expected run to incorrect, got: %v", brk)
+	}
+
+	vm.SetV(0, 0x42)
+	vm.WriteMemory(0x300, 0x99)
+	if vm.V(0) != 0x42 || vm.ReadMemory(0x300) != 0x99 {
+		t.Errorf("Expected edits incorrect")
+	}
+}
+
+func TestWatchpoint(t *testing.T) {
+	rombytes := []byte{
+		0xA3, 0x00, // 0x200 LD I, 0x300
+		0x60, 0x7B, // 0x202 LD V0, 123
+		0xF0, 0x33, // 0x204 LD B, V0
+		0xF2, 0x65, // 0x206 LD V2, [I]
+		0xD0, 0x13, // 0x208 DRW V0, V1, 3
+		0x12, 0x0A, // 0x20A JP 0x20A
+	}
+	vm := New(Config{CyclesPerFrame: 10, Quirks: Quirks{LoadStore: true}})
+	if err := vm.LoadROM(rombytes); err != nil {
+		t.Fatal(err)
+	}
+	if vm.memoryHook != nil {
+		t.Errorf("Expected no memory hook without watchpoints")
+	}
+	debugger := NewDebugger(vm)
+	write, _ := debugger.AddBreakpoint("w:302")
+	read, _ := debugger.AddBreakpoint("r:300-301")
+
+	brk := runToBreak(t, vm)
+	if brk.Breakpoint != write.ID || brk.Access == nil || brk.Access.PC != 0x204 || !brk.Access.Write || brk.Access.Size != 3 ||
+		vm.pc != 0x206 || vm.memory[0x302] != 3 {
+		t.Errorf("Expected write watchpoint incorrect, got: %v", brk)
+	}
+
+	debugger.Continue()
+	brk = runToBreak(t, vm)
+	if brk.Breakpoint != read.ID || brk.Access.PC != 0x206 || brk.Access.Write || vm.v[2] != 3 {
+		t.Errorf("Expected read watchpoint incorrect, got: %v", brk)
+	}
+
+	debugger.Continue()
+	brk = runToBreak(t, vm)
+	if brk.Breakpoint != read.ID || brk.Access.PC != 0x208 || vm.pc != 0x20A {
+		t.Errorf("Expected sprite read watchpoint incorrect, got: %v", brk)
+	}
+
+	debugger.RemoveBreakpoint(write.ID)
+	debugger.RemoveBreakpoint(read.ID)
+	if vm.memoryHook != nil {
+		t.Errorf("Expected the memory hook to be removed with the last watchpoint")
+	}
+}
+
+func TestBreakpointIRange(t *testing.T) {
+	vm, debugger := returnDebugVM(t)
+	vm.WriteMemory(0x204, 0xA3) // 0x204 LD I, 0x305
+	vm.WriteMemory(0x205, 0x05)
+	if _, err := debugger.AddBreakpoint("i:300-30f"); err != nil {
+		t.Fatal(err)
+	}
+	runToBreak(t, vm)
+	if vm.pc != 0x206 || vm.i != 0x305 {
+		t.Errorf("Expected I range break incorrect, got PC: 0x%x I: 0x%x", vm.pc, vm.i)
+	}
+}
+
+func TestBreakpoint_displayWait(t *testing.T) {
+	vm := New(Config{CyclesPerFrame: 10, Quirks: QuirksPresets["vip"]})
+	// DRW V0, V0, 1; ADD V0, 1; JP 0x200
+	if err := vm.LoadROM([]byte{0xD0, 0x01, 0x70, 0x01, 0x12, 0x00}); err != nil {
+		t.Fatal(err)
+	}
+	debugger := NewDebugger(vm)
+	bp, err := debugger.AddBreakpoint("0x202")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for frame := 0; frame < 30; frame++ {
+		for {
+			_, err := vm.SkipFrame()
+			var brk *Break
+			if !errors.As(err, &brk) {
+				break
+			}
+			debugger.Continue()
+		}
+	}
+	// the wait after each Dxyn doesn't hit the breakpoint, only ADD at the start of the next frame
+	if hits := debugger.Breakpoints()[0].Hits; hits != 29 || vm.v[0] != 29 {
+		t.Errorf("Expected breakpoint hits incorrect, got: %d, V0: %d", hits, vm.v[0])
+	}
+
+	debugger.RemoveBreakpoint(bp.ID)
+	debugger.Interrupt()
+	runToBreak(t, vm)
+	for vm.pc != 0x202 {
+		debugger.Step()
+	}
+	v0 := vm.v[0]
+	debugger.Step()
+	if vm.pc != 0x204 || vm.v[0] != v0+1 || !debugger.Paused() {
+		t.Errorf("Expected step through the display wait incorrect, got PC: 0x%x, V0: %d", vm.pc, vm.v[0])
+	}
+}
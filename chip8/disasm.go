@@ -0,0 +1,159 @@
+package chip8
+
+import "fmt"
+
+// Disassemble : Mnemonic of the instruction at the start of code and its size in bytes
+// F000 nnnn is 4 bytes long, every other instruction is 2. Data that isn't a known
+// instruction is shown as a DW directive.
+func Disassemble(code []byte) (string, int) {
+	if len(code) < 2 {
+		if len(code) == 1 {
+			return fmt.Sprintf("DB 0x%02X", code[0]), 1
+		}
+		return "", 0
+	}
+	opcode := uint16(code[0])<<8 | uint16(code[1])
+	x := opcode >> 8 & 0xF
+	y := opcode >> 4 & 0xF
+	n := opcode & 0xF
+	kk := opcode & 0xFF
+	nnn := opcode & 0xFFF
+
+	switch opcode & 0xF000 {
+	case 0x0000:
+		switch {
+		case opcode == 0x00E0:
+			return "CLS", 2
+		case opcode == 0x00EE:
+			return "RET", 2
+		case opcode == 0x00FB:
+			return "SCR", 2
+		case opcode == 0x00FC:
+			return "SCL", 2
+		case opcode == 0x00FD:
+			return "EXIT", 2
+		case opcode == 0x00FE:
+			return "LOW", 2
+		case opcode == 0x00FF:
+			return "HIGH", 2
+		case opcode&0xFFF0 == 0x00C0:
+			return fmt.Sprintf("SCD %d", n), 2
+		case opcode&0xFFF0 == 0x00D0:
+			return fmt.Sprintf("SCU %d", n), 2
+		}
+		return fmt.Sprintf("SYS 0x%03X", nnn), 2
+	case 0x1000:
+		return fmt.Sprintf("JP 0x%03X", nnn), 2
+	case 0x2000:
+		return fmt.Sprintf("CALL 0x%03X", nnn), 2
+	case 0x3000:
+		return fmt.Sprintf("SE V%X, 0x%02X", x, kk), 2
+	case 0x4000:
+		return fmt.Sprintf("SNE V%X, 0x%02X", x, kk), 2
+	case 0x5000:
+		switch n {
+		case 0x0:
+			return fmt.Sprintf("SE V%X, V%X", x, y), 2
+		case 0x2:
+			return fmt.Sprintf("SAVE V%X - V%X", x, y), 2
+		case 0x3:
+			return fmt.Sprintf("LOAD V%X - V%X", x, y), 2
+		}
+	case 0x6000:
+		return fmt.Sprintf("LD V%X, 0x%02X", x, kk), 2
+	case 0x7000:
+		return fmt.Sprintf("ADD V%X, 0x%02X", x, kk), 2
+	case 0x8000:
+		switch n {
+		case 0x0:
+			return fmt.Sprintf("LD V%X, V%X", x, y), 2
+		case 0x1:
+			return fmt.Sprintf("OR V%X, V%X", x, y), 2
+		case 0x2:
+			return fmt.Sprintf("AND V%X, V%X", x, y), 2
+		case 0x3:
+			return fmt.Sprintf("XOR V%X, V%X", x, y), 2
+		case 0x4:
+			return fmt.Sprintf("ADD V%X, V%X", x, y), 2
+		case 0x5:
+			return fmt.Sprintf("SUB V%X, V%X", x, y), 2
+		case 0x6:
+			return fmt.Sprintf("SHR V%X, V%X", x, y), 2
+		case 0x7:
+			return fmt.Sprintf("SUBN V%X, V%X", x, y), 2
+		case 0xE:
+			return fmt.Sprintf("SHL V%X, V%X", x, y), 2
+		}
+	case 0x9000:
+		if n == 0 {
+			return fmt.Sprintf("SNE V%X, V%X", x, y), 2
+		}
+	case 0xA000:
+		return fmt.Sprintf("LD I, 0x%03X", nnn), 2
+	case 0xB000:
+		return fmt.Sprintf("JP V0, 0x%03X", nnn), 2
+	case 0xC000:
+		return fmt.Sprintf("RND V%X, 0x%02X", x, kk), 2
+	case 0xD000:
+		return fmt.Sprintf("DRW V%X, V%X, %d", x, y, n), 2
+	case 0xE000:
+		switch kk {
+		case 0x9E:
+			return fmt.Sprintf("SKP V%X", x), 2
+		case 0xA1:
+			return fmt.Sprintf("SKNP V%X", x), 2
+		}
+	case 0xF000:
+		switch kk {
+		case 0x00:
+			if opcode == 0xF000 && len(code) >= 4 {
+				return fmt.Sprintf("LD I, long 0x%04X", uint16(code[2])<<8|uint16(code[3])), 4
+			}
+		case 0x01:
+			return fmt.Sprintf("PLANE %d", x), 2
+		case 0x02:
+			if opcode == 0xF002 {
+				return "AUDIO", 2
+			}
+		case 0x07:
+			return fmt.Sprintf("LD V%X, DT", x), 2
+		case 0x0A:
+			return fmt.Sprintf("LD V%X, K", x), 2
+		case 0x15:
+			return fmt.Sprintf("LD DT, V%X", x), 2
+		case 0x18:
+			return fmt.Sprintf("LD ST, V%X", x), 2
+		case 0x1E:
+			return fmt.Sprintf("ADD I, V%X", x), 2
+		case 0x29:
+			return fmt.Sprintf("LD F, V%X", x), 2
+		case 0x30:
+			return fmt.Sprintf("LD HF, V%X", x), 2
+		case 0x33:
+			return fmt.Sprintf("LD B, V%X", x), 2
+		case 0x3A:
+			return fmt.Sprintf("PITCH V%X", x), 2
+		case 0x55:
+			return fmt.Sprintf("LD [I], V%X", x), 2
+		case 0x65:
+			return fmt.Sprintf("LD V%X, [I]", x), 2
+		case 0x75:
+			return fmt.Sprintf("LD R, V%X", x), 2
+		case 0x85:
+			return fmt.Sprintf("LD V%X, R", x), 2
+		}
+	}
+	return fmt.Sprintf("DW 0x%04X", opcode), 2
+}
+
+// DisassembleAt : Mnemonic and size of the instruction at addr in memory
+func (vm *Machine) DisassembleAt(addr uint16) (string, int) {
+	end := int(addr) + 4
+	if end > vm.memSize {
+		end = vm.memSize
+	}
+	if int(addr) >= end {
+		return "", 0
+	}
+	return Disassemble(vm.memory[addr:end])
+}
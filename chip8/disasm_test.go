@@ -0,0 +1,59 @@
+package chip8
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisassemble(t *testing.T) {
+	cases := []struct {
+		code []byte
+		want string
+		size int
+	}{
+		{[]byte{0x00, 0xE0}, "CLS", 2},
+		{[]byte{0x12, 0x4E}, "JP 0x24E", 2},
+		{[]byte{0x6A, 0x02}, "LD VA, 0x02", 2},
+		{[]byte{0x8A, 0xB4}, "ADD VA, VB", 2},
+		{[]byte{0xD0, 0x15}, "DRW V0, V1, 5", 2},
+		{[]byte{0xF3, 0x65}, "LD V3, [I]", 2},
+		{[]byte{0xF0, 0x00, 0x12, 0x34}, "LD I, long 0x1234", 4},
+		{[]byte{0x5A, 0xB1}, "DW 0x5AB1", 2},
+		{[]byte{0xFF}, "DB 0xFF", 1},
+	}
+	for _, c := range cases {
+		got, size := Disassemble(c.code)
+		if got != c.want || size != c.size {
+			t.Errorf("Disassemble % x incorrect, got: %s (%d bytes), want: %s (%d bytes)", c.code, got, size, c.want, c.size)
+		}
+	}
+}
+
+func TestDisassembleROM(t *testing.T) {
+	rom := []byte{
+		0x00, 0xE0, // 0x200 CLS
+		0xA2, 0x12, // 0x202 LD I, 0x212
+		0x60, 0x00, // 0x204 LD V0, 0
+		0xD0, 0x05, // 0x206 DRW V0, V0, 5
+		0x22, 0x0C, // 0x208 CALL 0x20C
+		0x12, 0x0A, // 0x20A JP 0x20A
+		0xF1, 0x0A, // 0x20C LD V1, K
+		0xF1, 0x33, // 0x20E LD B, V1
+		0x00, 0xEE, // 0x210 RET
+		0xF0, 0x90, 0x90, 0x90, 0xF0, // 0x212 sprite
+		0x01, 0x02, 0x03, // 0x217 data
+	}
+	asm := DisassembleROM(rom)
+	for _, want := range []string{
+		"start:\n    CLS\n    LD I, sprite_212\n",
+		"CALL sub_20C\n",
+		"JP 0x20A               ; halt, jumping to itself\n",
+		"\nsub_20C:\n    LD V1, K               ; wait for a key press, into V1\n",
+		"\nsprite_212:\n    DB 0b11110000\n    DB 0b10010000\n",
+		"    DB 0b11110000\n    DB 0x01, 0x02, 0x03\n",
+	} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("Expected %q in disassembly:\n%s", want, asm)
+		}
+	}
+}
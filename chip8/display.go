@@ -0,0 +1,152 @@
+package chip8
+
+// setHires : Switch between 64x32 and 128x64 display modes, clearing the screen
+func (vm *Machine) setHires(hires bool) {
+	vm.hires = hires
+	if hires {
+		vm.width = 128
+		vm.height = 64
+	} else {
+		vm.width = 64
+		vm.height = 32
+	}
+	vm.screen = [2][64][16]uint8{}
+	vm.drawflag = true
+}
+
+// setVIPHires : Switch to the COSMAC VIP two-page 64x64 display mode, clearing the screen
+func (vm *Machine) setVIPHires() {
+	vm.hires = false
+	vm.width = 64
+	vm.height = 64
+	vm.screen = [2][64][16]uint8{}
+	vm.drawflag = true
+}
+
+// clearPlanes : Clear the selected bitplanes
+func (vm *Machine) clearPlanes() {
+	for p := 0; p < 2; p++ {
+		if vm.planes&(1<<p) != 0 {
+			vm.screen[p] = [64][16]uint8{}
+		}
+	}
+}
+
+// scrollDown : Scroll the selected bitplanes down n lines
+func (vm *Machine) scrollDown(n uint8) {
+	for p := 0; p < 2; p++ {
+		if vm.planes&(1<<p) == 0 {
+			continue
+		}
+		for y := int(vm.height) - 1; y >= 0; y-- {
+			if y >= int(n) {
+				vm.screen[p][y] = vm.screen[p][y-int(n)]
+			} else {
+				vm.screen[p][y] = [16]uint8{}
+			}
+		}
+	}
+}
+
+// scrollUp : Scroll the selected bitplanes up n lines
+func (vm *Machine) scrollUp(n uint8) {
+	for p := 0; p < 2; p++ {
+		if vm.planes&(1<<p) == 0 {
+			continue
+		}
+		for y := 0; y < int(vm.height); y++ {
+			if y+int(n) < int(vm.height) {
+				vm.screen[p][y] = vm.screen[p][y+int(n)]
+			} else {
+				vm.screen[p][y] = [16]uint8{}
+			}
+		}
+	}
+}
+
+// scrollRight : Scroll the selected bitplanes right by 4 pixels
+func (vm *Machine) scrollRight() {
+	wb := int(vm.width / 8)
+	for p := 0; p < 2; p++ {
+		if vm.planes&(1<<p) == 0 {
+			continue
+		}
+		for y := 0; y < int(vm.height); y++ {
+			for xb := wb - 1; xb > 0; xb-- {
+				vm.screen[p][y][xb] = vm.screen[p][y][xb]>>4 | vm.screen[p][y][xb-1]<<4
+			}
+			vm.screen[p][y][0] >>= 4
+		}
+	}
+}
+
+// scrollLeft : Scroll the selected bitplanes left by 4 pixels
+func (vm *Machine) scrollLeft() {
+	wb := int(vm.width / 8)
+	for p := 0; p < 2; p++ {
+		if vm.planes&(1<<p) == 0 {
+			continue
+		}
+		for y := 0; y < int(vm.height); y++ {
+			for xb := 0; xb < wb-1; xb++ {
+				vm.screen[p][y][xb] = vm.screen[p][y][xb]<<4 | vm.screen[p][y][xb+1]>>4
+			}
+			vm.screen[p][y][wb-1] <<= 4
+		}
+	}
+}
+
+// flipPixel : XOR a single pixel on bitplane p, returns true if the pixel was erased
+func (vm *Machine) flipPixel(p int, x uint8, y uint8) bool {
+	mask := uint8(0x80) >> (x % 8)
+	erased := vm.screen[p][y][x/8]&mask != 0
+	vm.screen[p][y][x/8] ^= mask
+	return erased
+}
+
+// spriteSize : Number of bytes read by drawSprite for a Dxyn sprite of n rows on the selected planes
+func (vm *Machine) spriteSize(n uint16) int {
+	size := int(n)
+	if n == 0 {
+		size = 32
+	}
+	return size * int(vm.planes&1+vm.planes>>1&1)
+}
+
+// drawSprite : XOR a w x h sprite from vm.i onto the screen at (x, y), setting vm.VF on collision
+// When both XO-CHIP bitplanes are selected the second plane's sprite data follows the first.
+func (vm *Machine) drawSprite(x uint8, y uint8, w uint16, h uint16) {
+	rowBytes := w / 8
+	addr := vm.i
+	for p := 0; p < 2; p++ {
+		if vm.planes&(1<<p) == 0 {
+			continue
+		}
+		for row := uint16(0); row < h; row++ {
+			py := uint16(y) + row
+			if py >= uint16(vm.height) {
+				if vm.wrapY != "on" {
+					break
+				}
+				py %= uint16(vm.height)
+			}
+			for col := uint16(0); col < w; col++ {
+				sprite := vm.memory[addr+row*rowBytes+col/8]
+				if sprite&(0x80>>(col%8)) == 0 {
+					continue
+				}
+				px := uint16(x) + col
+				if px >= uint16(vm.width) {
+					if vm.wrapX != "on" {
+						break
+					}
+					px %= uint16(vm.width)
+				}
+				if vm.flipPixel(p, uint8(px), uint8(py)) {
+					vm.v[0xF] = 1
+				}
+			}
+		}
+		addr += h * rowBytes
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Package chip8 is a CHIP-8, Super CHIP-8 and XO-CHIP interpreter that can be
+embedded in other programs. The SDL front-end in cmd/chip8go is one user of it.
+
+Create a Machine with New, load a ROM with LoadROM and drive it with a
+Scheduler (60Hz frames on the wall clock), with RunFrame (one frame of
+instructions followed by a timer tick and a render) on your own clock, or
+with Step and TickTimers for full control of timing:
+
+	vm := chip8.New(chip8.Config{Platform: chip8.PlatformSCHIP, Display: display, Keyboard: keyboard})
+	if err := vm.LoadROM(rom); err != nil {
+		log.Fatal(err)
+	}
+	scheduler := chip8.NewScheduler(vm, 5)
+	for {
+		running, err := scheduler.Tick()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !running {
+			break
+		}
+	}
+
+Output and input are pluggable through the Display, Keyboard and Audio
+interfaces, any of which may be nil. Tone generates the samples of a click-free
+tone for Audio implementations to play, and WAVRecorder records it to a file.
+On the xo-chip platform, Audio that also implements PatternAudio plays the
+F002 audio pattern at the Fx3A pitch instead of the tone.
+Screenshot renders the screen as an image, e.g. to encode as a PNG, and
+Animation records it every frame as an animated GIF or APNG. Registers,
+timers and memory can be read and written through the Machine accessors (V, SetV, I, PC, ReadMemory, ...).
+
+Cxkk draws random numbers from a generator seeded by Config.Seed, so a run
+depends only on the seed and the keys pressed. A Keyboard that also implements
+FrameKeyboard is read once at the start of each frame, and one that implements
+KeyPoller answers Fx0A without blocking. MovieRecorder records
+the keys of a run to a Movie, and MoviePlayer replays it exactly.
+
+Runtime faults such as a stack underflow or an unknown opcode are returned by
+Step and RunFrame as a *Fault, and Config.FaultPolicy chooses whether each
+kind halts the machine, is ignored, is logged or breaks into the caller:
+
+	if errors.Is(err, chip8.FaultUnknownOpcode) {
+		...
+	}
+
+A Debugger attached with NewDebugger adds breakpoints and stepping. Step then
+returns a *Break instead of running an instruction that hits a breakpoint, and
+the machine stays paused until Continue or one of the Debugger step methods.
+ListenGDB serves a Debugger over the GDB Remote Serial Protocol, with packets
+handled by Poll on the goroutine running the machine. ReadSymbols reads the
+labels and source lines of an assembled program, for debuggers to show.
+
+# API stability
+
+The exported identifiers in this package follow semantic versioning, with
+versions tagged on the github.com/jamesmcm/chip8go module: within a major
+version they will not be removed or change signature, and the Display,
+Keyboard and Audio interfaces will not gain methods. New Config fields, Machine
+methods and Quirks fields may be added, so construct Config and Quirks with
+field names rather than positional literals. The zero value of any new Config
+field keeps the previous behaviour.
+
+The exact instruction timing of Step, the values returned by PrintState and the
+contents of unused memory are not part of the API.
+*/
+package chip8
@@ -0,0 +1,234 @@
+package chip8
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expression : Condition or value over the machine state, compiled by ParseExpression
+type Expression func(vm *Machine) int
+
+// ParseExpression : Compile an expression such as "V3 == 0x10 && DT == 0" or "[I+1] > 5"
+// Operands are numbers (decimal, or hex with 0x), the registers V0-VF, I, PC, SP, DT and ST,
+// and memory bytes as [addr]. The operators are, from lowest to highest precedence:
+// ||, &&, comparisons (==, !=, <, <=, >, >=), bitwise |, ^ and &, + and -, then unary ! and -.
+// Comparisons and logical operators give 1 for true and 0 for false.
+func ParseExpression(s string) (Expression, error) {
+	tokens, err := tokenizeExpression(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in expression: %s", p.tokens[p.pos], s)
+	}
+	return expr, nil
+}
+
+var exprOperators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "|", "^", "&", "+", "-", "!", "(", ")", "[", "]"}
+
+func tokenizeExpression(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+			continue
+		case isAlphanumeric(c):
+			j := i
+			for j < len(s) && isAlphanumeric(s[j]) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+			continue
+		}
+		matched := false
+		for _, op := range exprOperators {
+			if strings.HasPrefix(s[i:], op) {
+				tokens = append(tokens, op)
+				i += len(op)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unexpected %q in expression: %s", c, s)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return tokens, nil
+}
+
+func isAlphanumeric(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// binary : Parse operands of next separated by any of ops, left to right
+func (p *exprParser) binary(next func() (Expression, error), ops ...string) (Expression, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		found := false
+		for _, o := range ops {
+			found = found || o == op
+		}
+		if !found {
+			return left, nil
+		}
+		p.pos++
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryOperator(op, left, right)
+	}
+}
+
+func (p *exprParser) or() (Expression, error)  { return p.binary(p.and, "||") }
+func (p *exprParser) and() (Expression, error) { return p.binary(p.comparison, "&&") }
+func (p *exprParser) comparison() (Expression, error) {
+	return p.binary(p.bitwise, "==", "!=", "<", "<=", ">", ">=")
+}
+func (p *exprParser) bitwise() (Expression, error)  { return p.binary(p.additive, "|", "^", "&") }
+func (p *exprParser) additive() (Expression, error) { return p.binary(p.unary, "+", "-") }
+
+func (p *exprParser) unary() (Expression, error) {
+	switch p.peek() {
+	case "!":
+		p.pos++
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(vm *Machine) int { return truth(operand(vm) == 0) }, nil
+	case "-":
+		p.pos++
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(vm *Machine) int { return -operand(vm) }, nil
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (Expression, error) {
+	token := p.peek()
+	if token == "" {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	switch token {
+	case "(", "[":
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		closing := map[string]string{"(": ")", "[": "]"}[token]
+		if p.peek() != closing {
+			return nil, fmt.Errorf("missing %q in expression", closing)
+		}
+		p.pos++
+		if token == "(" {
+			return inner, nil
+		}
+		return func(vm *Machine) int {
+			addr := inner(vm)
+			if addr < 0 || addr >= vm.memSize {
+				return 0
+			}
+			return int(vm.memory[addr])
+		}, nil
+	}
+	if register, err := parseRegister(token); err == nil {
+		return register, nil
+	}
+	value, err := strconv.ParseInt(token, 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unknown operand %q in expression", token)
+	}
+	return func(vm *Machine) int { return int(value) }, nil
+}
+
+func binaryOperator(op string, left, right Expression) Expression {
+	switch op {
+	case "||":
+		return func(vm *Machine) int { return truth(left(vm) != 0 || right(vm) != 0) }
+	case "&&":
+		return func(vm *Machine) int { return truth(left(vm) != 0 && right(vm) != 0) }
+	case "==":
+		return func(vm *Machine) int { return truth(left(vm) == right(vm)) }
+	case "!=":
+		return func(vm *Machine) int { return truth(left(vm) != right(vm)) }
+	case "<":
+		return func(vm *Machine) int { return truth(left(vm) < right(vm)) }
+	case "<=":
+		return func(vm *Machine) int { return truth(left(vm) <= right(vm)) }
+	case ">":
+		return func(vm *Machine) int { return truth(left(vm) > right(vm)) }
+	case ">=":
+		return func(vm *Machine) int { return truth(left(vm) >= right(vm)) }
+	case "|":
+		return func(vm *Machine) int { return left(vm) | right(vm) }
+	case "^":
+		return func(vm *Machine) int { return left(vm) ^ right(vm) }
+	case "&":
+		return func(vm *Machine) int { return left(vm) & right(vm) }
+	case "+":
+		return func(vm *Machine) int { return left(vm) + right(vm) }
+	}
+	return func(vm *Machine) int { return left(vm) - right(vm) }
+}
+
+func truth(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// parseRegister : Getter for a register named V0-VF, I, PC, SP, DT or ST
+func parseRegister(name string) (Expression, error) {
+	switch strings.ToUpper(name) {
+	case "I":
+		return func(vm *Machine) int { return int(vm.i) }, nil
+	case "PC":
+		return func(vm *Machine) int { return int(vm.pc) }, nil
+	case "SP":
+		return func(vm *Machine) int { return int(vm.sp) }, nil
+	case "DT":
+		return func(vm *Machine) int { return int(vm.delayTimer) }, nil
+	case "ST":
+		return func(vm *Machine) int { return int(vm.soundTimer) }, nil
+	}
+	if len(name) == 2 && (name[0] == 'V' || name[0] == 'v') {
+		if x, err := strconv.ParseUint(name[1:], 16, 8); err == nil {
+			return func(vm *Machine) int { return int(vm.v[x]) }, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown register: %s", name)
+}
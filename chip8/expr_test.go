@@ -0,0 +1,49 @@
+package chip8
+
+import "testing"
+
+func TestParseExpression(t *testing.T) {
+	vm := New(Config{})
+	vm.v[3] = 0x10
+	vm.i = 0x300
+	vm.memory[0x301] = 7
+	vm.delayTimer = 0
+
+	cases := []struct {
+		expr string
+		want int
+	}{
+		{"V3 == 0x10 && DT == 0", 1},
+		{"V3 == 16 && DT != 0", 0},
+		{"v3 > 1 || [I] == 1", 1},
+		{"[I+1] * 2", -1},
+		{"[I+1]", 7},
+		{"[i + 1] - 10", -3},
+		{"!(V3 & 0x10)", 0},
+		{"V3 | 1 ^ 3", 0x12},
+		{"-V3 < 0", 1},
+		{"PC == 0x200 && SP == 0", 1},
+	}
+	for _, c := range cases {
+		expr, err := ParseExpression(c.expr)
+		if c.want == -1 {
+			if err == nil {
+				t.Errorf("Expected an error for %q", c.expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expression %q failed: %v", c.expr, err)
+			continue
+		}
+		if got := expr(vm); got != c.want {
+			t.Errorf("Expression %q incorrect, got: %d, want: %d", c.expr, got, c.want)
+		}
+	}
+
+	for _, s := range []string{"", "V3 ==", "(V3", "[I", "V3 = 1", "VX"} {
+		if _, err := ParseExpression(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package chip8
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FaultKind : Type of runtime fault, usable with errors.Is on a returned *Fault
+type FaultKind int
+
+// Runtime faults
+const (
+	FaultStackOverflow   FaultKind = iota // 2nnn with a full stack
+	FaultStackUnderflow                   // 00EE with an empty stack
+	FaultIllegalJump                      // 1nnn/2nnn outside of program memory
+	FaultMemoryAccess                     // read or write outside of memory
+	FaultUnknownOpcode                    // opcode not supported on this platform
+	FaultDrawOutOfBounds                  // Dxyn off-screen with the "error" wrap mode
+	numFaultKinds
+)
+
+var faultKindNames = [numFaultKinds]string{
+	"stack-overflow",
+	"stack-underflow",
+	"illegal-jump",
+	"memory-access",
+	"unknown-opcode",
+	"draw-out-of-bounds",
+}
+
+// String : Name of the fault kind as used by ParseFaultPolicies, e.g. "stack-underflow"
+func (kind FaultKind) String() string {
+	if kind < 0 || kind >= numFaultKinds {
+		return fmt.Sprintf("fault-%d", int(kind))
+	}
+	return faultKindNames[kind]
+}
+
+// Error : Description of the fault kind, e.g. "stack underflow"
+func (kind FaultKind) Error() string {
+	return strings.Replace(kind.String(), "-", " ", -1)
+}
+
+// FaultPolicy : Action taken by Step when an instruction faults
+type FaultPolicy int
+
+// Fault policies, the zero value halts
+const (
+	PolicyHalt   FaultPolicy = iota // stop the machine, Step returns false and the fault from now on
+	PolicyIgnore                    // skip the faulting instruction and continue
+	PolicyLog                       // log the fault, then skip the faulting instruction and continue
+	PolicyBreak                     // return the fault without executing the instruction, the machine can continue
+	numFaultPolicies
+)
+
+var faultPolicyNames = [numFaultPolicies]string{"halt", "ignore", "log", "break"}
+
+// String : Name of the policy as used by ParseFaultPolicies
+func (policy FaultPolicy) String() string {
+	if policy < 0 || policy >= numFaultPolicies {
+		return fmt.Sprintf("policy-%d", int(policy))
+	}
+	return faultPolicyNames[policy]
+}
+
+// Fault : Runtime fault raised by the instruction at PC, with the register state at the time
+type Fault struct {
+	Kind   FaultKind
+	PC     uint16
+	Opcode uint16
+	Addr   uint16 // target address for illegal jumps, first address for memory accesses
+	Size   int    // number of bytes for memory accesses
+	V      [16]uint8
+	I      uint16
+	SP     uint16
+}
+
+func (fault *Fault) Error() string {
+	var what string
+	switch fault.Kind {
+	case FaultIllegalJump:
+		what = fmt.Sprintf(" to 0x%x", fault.Addr)
+	case FaultMemoryAccess:
+		what = fmt.Sprintf(" at 0x%x (%d bytes)", fault.Addr, fault.Size)
+	}
+	return fmt.Sprintf("%s%s - PC: 0x%x, opcode: 0x%04x, I: 0x%x, SP: %d, V: % 02x",
+		fault.Kind.Error(), what, fault.PC, fault.Opcode, fault.I, fault.SP, fault.V)
+}
+
+// Unwrap : The fault kind, so errors.Is(err, chip8.FaultStackUnderflow) matches
+func (fault *Fault) Unwrap() error {
+	return fault.Kind
+}
+
+// newFault : Fault of the given kind for the current instruction
+func (vm *Machine) newFault(kind FaultKind, addr uint16) *Fault {
+	return &Fault{Kind: kind, PC: vm.pc, Opcode: vm.opcode, Addr: addr, V: vm.v, I: vm.i, SP: vm.sp}
+}
+
+// checkMemory : Fault if any of the size bytes from addr are outside of memory
+func (vm *Machine) checkMemory(addr uint16, size int) *Fault {
+	if int(addr)+size <= vm.memSize {
+		return nil
+	}
+	fault := vm.newFault(FaultMemoryAccess, addr)
+	fault.Size = size
+	return fault
+}
+
+// accessMemory : Fault if the access is outside of memory, otherwise report it to the memory hook
+func (vm *Machine) accessMemory(addr uint16, size int, write bool) *Fault {
+	if fault := vm.checkMemory(addr, size); fault != nil {
+		return fault
+	}
+	if vm.memoryHook != nil {
+		vm.memoryHook(addr, size, write)
+	}
+	return nil
+}
+
+// ParseFaultPolicies : Parse policies of the form "log,stack-underflow=halt,unknown-opcode=ignore"
+// A policy without a fault kind applies to every kind not listed.
+func ParseFaultPolicies(s string) (map[FaultKind]FaultPolicy, error) {
+	policies := make(map[FaultKind]FaultPolicy)
+	explicit := make(map[FaultKind]bool)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kindName, policyName := "", entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			kindName, policyName = entry[:i], entry[i+1:]
+		}
+		policy, ok := parseFaultPolicy(policyName)
+		if !ok {
+			return nil, fmt.Errorf("unknown fault policy: %s", policyName)
+		}
+		if kindName == "" {
+			for kind := FaultKind(0); kind < numFaultKinds; kind++ {
+				if !explicit[kind] {
+					policies[kind] = policy
+				}
+			}
+			continue
+		}
+		kind, ok := parseFaultKind(kindName)
+		if !ok {
+			return nil, fmt.Errorf("unknown fault: %s", kindName)
+		}
+		policies[kind] = policy
+		explicit[kind] = true
+	}
+	return policies, nil
+}
+
+func parseFaultKind(name string) (FaultKind, bool) {
+	for kind, kindName := range faultKindNames {
+		if kindName == name {
+			return FaultKind(kind), true
+		}
+	}
+	return 0, false
+}
+
+func parseFaultPolicy(name string) (FaultPolicy, bool) {
+	for policy, policyName := range faultPolicyNames {
+		if policyName == name {
+			return FaultPolicy(policy), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,481 @@
+package chip8
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gdbTargetXML : Target description of the registers, in the order of the g packet
+// V0-VF are registers 0-15, then I, PC, SP, DT and ST. Values are sent little-endian.
+const gdbTargetXML = `<?xml version="1.0"?>
+<!DOCTYPE target SYSTEM "gdb-target.dtd">
+<target version="1.0">
+  <feature name="org.chip8go.cpu">
+    <reg name="v0" bitsize="8" type="uint8" regnum="0"/>
+    <reg name="v1" bitsize="8" type="uint8"/>
+    <reg name="v2" bitsize="8" type="uint8"/>
+    <reg name="v3" bitsize="8" type="uint8"/>
+    <reg name="v4" bitsize="8" type="uint8"/>
+    <reg name="v5" bitsize="8" type="uint8"/>
+    <reg name="v6" bitsize="8" type="uint8"/>
+    <reg name="v7" bitsize="8" type="uint8"/>
+    <reg name="v8" bitsize="8" type="uint8"/>
+    <reg name="v9" bitsize="8" type="uint8"/>
+    <reg name="va" bitsize="8" type="uint8"/>
+    <reg name="vb" bitsize="8" type="uint8"/>
+    <reg name="vc" bitsize="8" type="uint8"/>
+    <reg name="vd" bitsize="8" type="uint8"/>
+    <reg name="ve" bitsize="8" type="uint8"/>
+    <reg name="vf" bitsize="8" type="uint8"/>
+    <reg name="i" bitsize="16" type="data_ptr"/>
+    <reg name="pc" bitsize="16" type="code_ptr"/>
+    <reg name="sp" bitsize="16" type="uint16"/>
+    <reg name="dt" bitsize="8" type="uint8"/>
+    <reg name="st" bitsize="8" type="uint8"/>
+  </feature>
+</target>
+`
+
+// gdbRegisterSizes : Size in bytes of each register in the target description
+var gdbRegisterSizes = [21]int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 2, 2, 1, 1}
+
+// GDBServer : GDB Remote Serial Protocol stub for a Machine, serving one client at a time
+// Packets are read on a background goroutine and handled by Poll, so the machine is only
+// touched by the goroutine running it.
+type GDBServer struct {
+	debugger    *Debugger
+	vm          *Machine
+	listener    net.Listener
+	events      chan gdbEvent
+	mu          sync.Mutex     // guards changes to conn, which Close reads on another goroutine
+	conn        net.Conn       // connected client, nil when there is none
+	waitStop    bool           // reply to the client once the machine stops
+	breakpoints map[string]int // Z packet arguments to debugger breakpoint IDs
+}
+
+// gdbEvent : Packet, interrupt, connection or disconnection from a client
+type gdbEvent struct {
+	conn      net.Conn
+	packet    string
+	interrupt bool
+	connected bool
+	closed    bool
+}
+
+// ListenGDB : Serve the debugger over the GDB Remote Serial Protocol on a TCP address, e.g. "localhost:2345"
+// The machine is paused when a client connects, and resumed when it detaches.
+func ListenGDB(debugger *Debugger, addr string) (*GDBServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	server := &GDBServer{
+		debugger:    debugger,
+		vm:          debugger.vm,
+		listener:    listener,
+		events:      make(chan gdbEvent, 16),
+		breakpoints: make(map[string]int),
+	}
+	go server.accept()
+	return server, nil
+}
+
+// Addr : Address the server is listening on
+func (server *GDBServer) Addr() net.Addr { return server.listener.Addr() }
+
+// Close : Stop listening and disconnect the client
+func (server *GDBServer) Close() error {
+	server.mu.Lock()
+	if server.conn != nil {
+		server.conn.Close()
+	}
+	server.mu.Unlock()
+	return server.listener.Close()
+}
+
+// setConn : Change the connected client, only called by the goroutine running Poll
+func (server *GDBServer) setConn(conn net.Conn) {
+	server.mu.Lock()
+	server.conn = conn
+	server.mu.Unlock()
+}
+
+func (server *GDBServer) accept() {
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+		server.events <- gdbEvent{conn: conn, connected: true}
+		server.read(conn)
+		server.events <- gdbEvent{conn: conn, closed: true}
+	}
+}
+
+// read : Read packets from the client until it disconnects, acknowledging each one
+func (server *GDBServer) read(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		switch b {
+		case 0x03:
+			server.events <- gdbEvent{conn: conn, interrupt: true}
+		case '$':
+			data, err := r.ReadString('#')
+			if err != nil {
+				return
+			}
+			data = data[:len(data)-1]
+			var sum [2]byte
+			if _, err := io.ReadFull(r, sum[:]); err != nil {
+				return
+			}
+			if fmt.Sprintf("%02x", gdbChecksum(data)) != strings.ToLower(string(sum[:])) {
+				conn.Write([]byte("-"))
+				continue
+			}
+			conn.Write([]byte("+"))
+			server.events <- gdbEvent{conn: conn, packet: data}
+		}
+	}
+}
+
+func gdbChecksum(data string) uint8 {
+	var sum uint8
+	for i := 0; i < len(data); i++ {
+		sum += data[i]
+	}
+	return sum
+}
+
+// Poll : Handle the packets received since the last call, and report to the client if the
+// machine has stopped. Called regularly by the goroutine running the machine.
+func (server *GDBServer) Poll() {
+	for {
+		select {
+		case event := <-server.events:
+			server.handle(event)
+			continue
+		default:
+		}
+		break
+	}
+	if server.waitStop && server.conn != nil && server.stopped() {
+		server.waitStop = false
+		server.send(server.stopReply())
+	}
+}
+
+func (server *GDBServer) handle(event gdbEvent) {
+	switch {
+	case event.connected:
+		server.setConn(event.conn)
+		server.waitStop = false
+		server.debugger.Interrupt()
+	case event.conn != server.conn:
+		// events from a client that has since been replaced
+	case event.closed:
+		server.detach()
+		server.setConn(nil)
+	case event.interrupt:
+		server.debugger.Interrupt()
+		server.waitStop = true
+	default:
+		if reply, wait := server.command(event.packet); wait {
+			server.waitStop = true
+		} else {
+			server.send(reply)
+		}
+	}
+}
+
+// detach : Remove the client's breakpoints and resume the machine
+func (server *GDBServer) detach() {
+	for key, id := range server.breakpoints {
+		server.debugger.RemoveBreakpoint(id)
+		delete(server.breakpoints, key)
+	}
+	server.waitStop = false
+	if server.debugger.Paused() {
+		server.debugger.Continue()
+	}
+}
+
+func (server *GDBServer) send(reply string) {
+	if server.conn != nil {
+		fmt.Fprintf(server.conn, "$%s#%02x", reply, gdbChecksum(reply))
+	}
+}
+
+func (server *GDBServer) stopped() bool {
+	return server.debugger.Paused() || server.vm.Halted() != NotHalted
+}
+
+// stopReply : Stop reason packet, SIGTRAP for breaks, SIGSEGV for faults and exited once halted
+func (server *GDBServer) stopReply() string {
+	switch server.vm.Halted() {
+	case NotHalted:
+	case HaltFault:
+		return "S0b"
+	default:
+		return "W00"
+	}
+	brk := server.debugger.Stopped()
+	switch {
+	case brk == nil:
+		return "S0b"
+	case brk.Access != nil:
+		kind := "rwatch"
+		for _, bp := range server.debugger.breakpoints {
+			if bp.ID == brk.Breakpoint && bp.watchRead && bp.watchWrite {
+				kind = "awatch"
+			}
+		}
+		if brk.Access.Write && kind != "awatch" {
+			kind = "watch"
+		}
+		return fmt.Sprintf("T05%s:%x;", kind, brk.Access.Addr)
+	}
+	return "S05"
+}
+
+// command : Reply to a packet, or wait for the machine to stop before replying
+func (server *GDBServer) command(packet string) (string, bool) {
+	vm := server.vm
+	if packet == "" {
+		return "", false
+	}
+	args := packet[1:]
+	switch packet[0] {
+	case '?':
+		if server.stopped() {
+			return server.stopReply(), false
+		}
+		server.debugger.Interrupt()
+		return "", true
+	case 'g':
+		var sb strings.Builder
+		for n := range gdbRegisterSizes {
+			sb.WriteString(server.register(n))
+		}
+		return sb.String(), false
+	case 'G':
+		data, err := hex.DecodeString(args)
+		if err != nil {
+			return "E01", false
+		}
+		for n, size := range gdbRegisterSizes {
+			if len(data) < size {
+				break
+			}
+			server.setRegister(n, littleEndian(data[:size]))
+			data = data[size:]
+		}
+		return "OK", false
+	case 'p':
+		n, err := strconv.ParseUint(args, 16, 8)
+		if err != nil || int(n) >= len(gdbRegisterSizes) {
+			return "E01", false
+		}
+		return server.register(int(n)), false
+	case 'P':
+		parts := strings.SplitN(args, "=", 2)
+		n, err := strconv.ParseUint(parts[0], 16, 8)
+		if err != nil || len(parts) != 2 || int(n) >= len(gdbRegisterSizes) {
+			return "E01", false
+		}
+		data, err := hex.DecodeString(parts[1])
+		if err != nil || !server.setRegister(int(n), littleEndian(data)) {
+			return "E01", false
+		}
+		return "OK", false
+	case 'm':
+		addr, length, ok := server.memoryRange(args)
+		if !ok {
+			return "E01", false
+		}
+		return hex.EncodeToString(vm.memory[addr : addr+length]), false
+	case 'M':
+		parts := strings.SplitN(args, ":", 2)
+		addr, length, ok := server.memoryRange(parts[0])
+		if !ok || len(parts) != 2 {
+			return "E01", false
+		}
+		data, err := hex.DecodeString(parts[1])
+		if err != nil || len(data) != length {
+			return "E01", false
+		}
+		copy(vm.memory[addr:], data)
+		return "OK", false
+	case 'Z', 'z':
+		return server.breakpoint(packet[0] == 'Z', args), false
+	case 's':
+		if args != "" {
+			if addr, err := strconv.ParseUint(args, 16, 16); err == nil {
+				vm.pc = uint16(addr)
+			}
+		}
+		server.debugger.Step()
+		return server.stopReply(), false
+	case 'c':
+		if args != "" {
+			if addr, err := strconv.ParseUint(args, 16, 16); err == nil {
+				vm.pc = uint16(addr)
+			}
+		}
+		server.debugger.Continue()
+		return "", true
+	case 'D':
+		server.detach()
+		return "OK", false
+	case 'k':
+		server.detach()
+		if server.conn != nil {
+			server.conn.Close()
+		}
+		server.setConn(nil) // no reply to a kill, and later events from the connection are ignored
+		return "", false
+	case 'H':
+		return "OK", false
+	}
+
+	switch {
+	case strings.HasPrefix(packet, "qSupported"):
+		return "PacketSize=4000;qXfer:features:read+", false
+	case strings.HasPrefix(packet, "qXfer:features:read:target.xml:"):
+		var offset, length int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(packet, "qXfer:features:read:target.xml:"), "%x,%x", &offset, &length); err != nil {
+			return "E01", false
+		}
+		if offset >= len(gdbTargetXML) {
+			return "l", false
+		}
+		if offset+length >= len(gdbTargetXML) {
+			return "l" + gdbTargetXML[offset:], false
+		}
+		return "m" + gdbTargetXML[offset:offset+length], false
+	case packet == "qAttached":
+		return "1", false
+	case packet == "qC":
+		return "QC1", false
+	case packet == "qfThreadInfo":
+		return "m1", false
+	case packet == "qsThreadInfo":
+		return "l", false
+	}
+	return "", false
+}
+
+// register : Register n in the g packet order, as little-endian hex
+func (server *GDBServer) register(n int) string {
+	vm := server.vm
+	var value uint16
+	switch {
+	case n < 16:
+		value = uint16(vm.v[n])
+	case n == 16:
+		value = vm.i
+	case n == 17:
+		value = vm.pc
+	case n == 18:
+		value = vm.sp
+	case n == 19:
+		value = uint16(vm.delayTimer)
+	case n == 20:
+		value = uint16(vm.soundTimer)
+	}
+	if gdbRegisterSizes[n] == 1 {
+		return fmt.Sprintf("%02x", value)
+	}
+	return fmt.Sprintf("%02x%02x", value&0xFF, value>>8)
+}
+
+// setRegister : Set register n in the g packet order, false for SP which can't be set
+func (server *GDBServer) setRegister(n int, value uint16) bool {
+	vm := server.vm
+	switch {
+	case n < 16:
+		vm.v[n] = uint8(value)
+	case n == 16:
+		vm.i = value
+	case n == 17:
+		vm.pc = value
+	case n == 19:
+		vm.delayTimer = uint8(value)
+	case n == 20:
+		vm.soundTimer = uint8(value)
+	default:
+		return false
+	}
+	return true
+}
+
+func littleEndian(data []byte) uint16 {
+	var value uint16
+	for i := len(data) - 1; i >= 0; i-- {
+		value = value<<8 | uint16(data[i])
+	}
+	return value
+}
+
+// memoryRange : Parse "addr,length" in hex, false if it isn't within memory
+func (server *GDBServer) memoryRange(s string) (int, int, bool) {
+	var addr, length int
+	if _, err := fmt.Sscanf(s, "%x,%x", &addr, &length); err != nil {
+		return 0, 0, false
+	}
+	if addr < 0 || length < 0 || addr+length > server.vm.memSize {
+		return 0, 0, false
+	}
+	return addr, length, true
+}
+
+// breakpoint : Insert or remove a breakpoint (type 0 and 1) or watchpoint (type 2 write, 3 read, 4 access)
+func (server *GDBServer) breakpoint(insert bool, args string) string {
+	var kind, addr, length int
+	if _, err := fmt.Sscanf(args, "%d,%x,%x", &kind, &addr, &length); err != nil {
+		return "E01"
+	}
+	key := fmt.Sprintf("%d,%x,%x", kind, addr, length)
+	if !insert {
+		if id, ok := server.breakpoints[key]; ok {
+			server.debugger.RemoveBreakpoint(id)
+			delete(server.breakpoints, key)
+		}
+		return "OK"
+	}
+	if _, ok := server.breakpoints[key]; ok {
+		return "OK"
+	}
+	if length < 1 {
+		length = 1
+	}
+	var spec string
+	switch kind {
+	case 0, 1:
+		spec = fmt.Sprintf("0x%x", addr)
+	case 2:
+		spec = fmt.Sprintf("w:%x-%x", addr, addr+length-1)
+	case 3:
+		spec = fmt.Sprintf("r:%x-%x", addr, addr+length-1)
+	case 4:
+		spec = fmt.Sprintf("rw:%x-%x", addr, addr+length-1)
+	default:
+		return ""
+	}
+	bp, err := server.debugger.AddBreakpoint(spec)
+	if err != nil {
+		return "E01"
+	}
+	server.breakpoints[key] = bp.ID
+	return "OK"
+}
@@ -0,0 +1,139 @@
+package chip8
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// gdbClient : Minimal RSP client for tests
+type gdbClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (client *gdbClient) send(packet string) {
+	fmt.Fprintf(client.conn, "$%s#%02x", packet, gdbChecksum(packet))
+	if ack, err := client.r.ReadByte(); err != nil || ack != '+' {
+		client.t.Fatalf("Expected ack for %q, got: %q %v", packet, ack, err)
+	}
+}
+
+func (client *gdbClient) reply() string {
+	client.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := client.r.ReadString('$'); err != nil {
+		client.t.Fatal(err)
+	}
+	data, err := client.r.ReadString('#')
+	if err != nil {
+		client.t.Fatal(err)
+	}
+	client.r.Discard(2)
+	return strings.TrimSuffix(data, "#")
+}
+
+func (client *gdbClient) command(packet, want string) string {
+	client.t.Helper()
+	client.send(packet)
+	got := client.reply()
+	if want != "*" && got != want {
+		client.t.Errorf("Expected reply to %q: %q, got: %q", packet, want, got)
+	}
+	return got
+}
+
+func TestGDBServer(t *testing.T) {
+	vm, debugger := returnDebugVM(t)
+	server, err := ListenGDB(debugger, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// the machine runs on its own goroutine, as in the front end, stopping at the first instruction
+	debugger.Interrupt()
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			server.Poll()
+			if !debugger.Paused() {
+				vm.SkipFrame()
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	defer close(done)
+
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := &gdbClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+
+	if got := client.command("qSupported:xmlRegisters=i386", "*"); !strings.Contains(got, "qXfer:features:read+") {
+		t.Errorf("Expected target description support, got: %q", got)
+	}
+	if got := client.command("qXfer:features:read:target.xml:0,1000", "*"); !strings.HasPrefix(got, "l<?xml") || !strings.Contains(got, `name="st"`) {
+		t.Errorf("Expected target description, got: %q", got)
+	}
+	client.command("?", "S05")
+
+	client.command("Z0,208,2", "OK")
+	client.command("c", "S05")
+	client.command("p11", "0802")
+	if got := client.command("g", "*"); len(got) != (16+2*3+2)*2 || !strings.HasPrefix(got, "00") {
+		t.Errorf("Expected registers incorrect, got: %q", got)
+	}
+	client.command("s", "S05")
+	client.command("p0", "01")
+
+	client.command("P0=2a", "OK")
+	client.command("P10=0003", "OK")
+	client.command("P12=0100", "E01")
+	client.command("M300,2:beef", "OK")
+	client.command("m2fe,4", "0000beef")
+	client.command("m0fff,2", "E01")
+	client.command("p10", "0003")
+
+	client.command("z0,208,2", "OK")
+	client.command("Z2,300,1", "OK")
+	client.command("M210,4:f0551210", "OK") // 0x210 LD [I], V0; JP 0x210
+	client.command("P11=1002", "OK")
+	client.command("c", "T05watch:300;")
+	client.command("p11", "1202")
+	client.command("m300,1", "2a")
+
+	client.command("z2,300,1", "OK")
+	client.command("vCont?", "")
+	client.send("c")
+	conn.Write([]byte{0x03})
+	if got := client.reply(); got != "S05" {
+		t.Errorf("Expected interrupt stop, got: %q", got)
+	}
+	client.command("D", "OK")
+
+	// a kill closes the connection without a reply
+	conn.Close()
+	killed, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer killed.Close()
+	client = &gdbClient{t: t, conn: killed, r: bufio.NewReader(killed)}
+	client.command("?", "S05")
+	client.send("k")
+	killed.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if rest, err := client.r.ReadString('$'); err == nil || rest != "" {
+		t.Errorf("Expected the connection closed without a reply, got: %q, %v", rest, err)
+	}
+}
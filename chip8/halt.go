@@ -0,0 +1,104 @@
+package chip8
+
+import "fmt"
+
+// HaltReason : How the program stopped
+type HaltReason int
+
+// Halt reasons
+const (
+	NotHalted    HaltReason = iota
+	HaltExit                // 00FD exit instruction
+	HaltSelfJump            // 1nnn jumping to itself, the common halt idiom
+	HaltSpinLoop            // loop that can't change any state or read any input
+	HaltFault               // runtime fault with PolicyHalt, see Machine.Fault
+	HaltKeyboard            // keyboard closed while waiting for a key at Fx0A
+	numHaltReasons
+)
+
+var haltReasonNames = [numHaltReasons]string{"running", "exit", "self-jump", "spin-loop", "fault", "keyboard"}
+
+// String : Name of the halt reason
+func (reason HaltReason) String() string {
+	if reason < 0 || reason >= numHaltReasons {
+		return fmt.Sprintf("halt-%d", int(reason))
+	}
+	return haltReasonNames[reason]
+}
+
+// spinLoop : Registers at the last 1nnn jump, to detect loops that repeat without any effect
+type spinLoop struct {
+	target  uint16
+	v       [16]uint8
+	i       uint16
+	sp      uint16
+	stack   []uint16 // return addresses on the stack, a call and return can change them without changing sp
+	effects bool     // an instruction since the last jump depended on or changed state outside of the registers
+}
+
+// spinning : Called after each instruction, true if the machine is in a loop it can never leave
+// A jump back to the same target with the same registers and stack, and no input, timer, random,
+// memory writing or screen instructions in between, will repeat forever.
+func (vm *Machine) spinning() bool {
+	if vm.opcode&0xF000 != 0x1000 {
+		if hasEffects(vm.opcode) {
+			vm.spin.effects = true
+		}
+		return false
+	}
+	spin := &vm.spin
+	if vm.pc == spin.target && !spin.effects && vm.v == spin.v && vm.i == spin.i && vm.sp == spin.sp &&
+		equalStacks(vm.stack[:vm.sp], spin.stack) {
+		return true
+	}
+	*spin = spinLoop{target: vm.pc, v: vm.v, i: vm.i, sp: vm.sp, stack: append(spin.stack[:0], vm.stack[:vm.sp]...)}
+	return false
+}
+
+// equalStacks : Whether the return addresses are the same
+func equalStacks(a, b []uint16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasEffects : Whether the instruction depends on or changes state outside of the registers
+func hasEffects(opcode uint16) bool {
+	if writesMemory(opcode) {
+		return true
+	}
+	switch opcode & 0xF000 {
+	case 0x0000:
+		switch {
+		case opcode == 0x00E0, opcode == 0x0230, opcode >= 0x00FB && opcode <= 0x00FF:
+			return true
+		case opcode&0xFFF0 == 0x00C0, opcode&0xFFF0 == 0x00D0:
+			return true
+		}
+	case 0xC000, 0xD000, 0xE000:
+		return true
+	case 0xF000:
+		switch opcode & 0x00FF {
+		case 0x01, 0x02, 0x07, 0x0A, 0x15, 0x18, 0x3A, 0x75:
+			return true
+		}
+	}
+	return false
+}
+
+// writesMemory : Whether the instruction writes memory, which could change the loop's own code
+func writesMemory(opcode uint16) bool {
+	switch opcode & 0xF000 {
+	case 0x5000:
+		return opcode&0x000F == 0x0002 // 5xy2 - XO-CHIP save range
+	case 0xF000:
+		return opcode&0x00FF == 0x33 || opcode&0x00FF == 0x55
+	}
+	return false
+}
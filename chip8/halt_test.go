@@ -0,0 +1,111 @@
+package chip8
+
+import "testing"
+
+// runHalt : Run a ROM until it halts, with a cycle limit in case it never does
+func runHalt(rombytes []byte) *Machine {
+	return runHaltConfig(rombytes, Config{})
+}
+
+// runHaltConfig : Run a ROM as runHalt, with a config
+func runHaltConfig(rombytes []byte, config Config) *Machine {
+	vm := New(config)
+	vm.LoadROM(rombytes)
+	for c := 0; c < 100000; c++ {
+		if running, _ := vm.Step(); !running {
+			break
+		}
+	}
+	return vm
+}
+
+func TestHaltSelfJump(t *testing.T) {
+	rombytes := []byte{0x60, 0x01, 0x12, 0x02}
+	vm := runHalt(rombytes)
+
+	if vm.Halted() != HaltSelfJump || vm.pc != 0x202 {
+		t.Errorf("Expected halt incorrect, got: %s, PC: %x", vm.Halted(), vm.pc)
+	}
+	if running, err := vm.Step(); running || err != nil {
+		t.Errorf("Halted machine continued, got: %t, %v", running, err)
+	}
+}
+
+func TestHaltSpinLoop(t *testing.T) {
+	// loop that only skips over a jump out of it
+	rombytes := []byte{0x60, 0x05, 0x30, 0x05, 0x12, 0x0A, 0x12, 0x02, 0x00, 0x00, 0x60, 0x00}
+	vm := runHalt(rombytes)
+
+	if vm.Halted() != HaltSpinLoop || vm.pc != 0x202 {
+		t.Errorf("Expected halt incorrect, got: %s, PC: %x", vm.Halted(), vm.pc)
+	}
+}
+
+func TestHaltSpinLoop_stack(t *testing.T) {
+	// jumps to 0x210 with the same registers and sp, but returning to a different address each time
+	rombytes := []byte{
+		0x22, 0x08, 0x22, 0x0C, 0x60, 0x01, 0x12, 0x06,
+		0x12, 0x10, 0x00, 0x00, 0x12, 0x10, 0x00, 0x00,
+		0x00, 0xEE,
+	}
+	vm := runHalt(rombytes)
+
+	if vm.Halted() != HaltSelfJump || vm.v[0] != 0x01 || vm.pc != 0x206 {
+		t.Errorf("Expected halt incorrect, got: %s, PC: %x", vm.Halted(), vm.pc)
+	}
+}
+
+func TestHaltSpinLoop_memoryWrite(t *testing.T) {
+	// loop storing V0 each time around is not reported, the write could change the loop's code
+	rombytes := []byte{0xA3, 0x00, 0xF0, 0x55, 0x12, 0x00}
+	vm := runHalt(rombytes)
+
+	if vm.Halted() != NotHalted {
+		t.Errorf("Expected halt incorrect, got: %s, PC: %x", vm.Halted(), vm.pc)
+	}
+}
+
+func TestHaltTimerLoop(t *testing.T) {
+	// loop waiting for the delay timer is not halted
+	rombytes := []byte{0x60, 0x05, 0xF0, 0x15, 0xF1, 0x07, 0x31, 0x00, 0x12, 0x04, 0x12, 0x0A}
+	vm := New(Config{})
+	vm.LoadROM(rombytes)
+	for frame := 0; frame < 10; frame++ {
+		if running, _ := vm.RunFrame(); !running {
+			break
+		}
+	}
+
+	if vm.Halted() != HaltSelfJump || vm.pc != 0x20A {
+		t.Errorf("Expected halt incorrect, got: %s, PC: %x", vm.Halted(), vm.pc)
+	}
+}
+
+func TestHaltRuntimeCode(t *testing.T) {
+	// code copied past the end of the ROM keeps running: LD V5, 0x09; JP 0x302
+	rombytes := []byte{0x60, 0x65, 0x61, 0x09, 0x62, 0x13, 0x63, 0x02, 0xA3, 0x00, 0xF3, 0x55, 0x13, 0x00}
+	vm := runHalt(rombytes)
+
+	if vm.Halted() != HaltSelfJump || vm.v[5] != 0x09 || vm.pc != 0x302 {
+		t.Errorf("Expected halt incorrect, got: %s, PC: %x", vm.Halted(), vm.pc)
+	}
+}
+
+func TestHaltExit(t *testing.T) {
+	rombytes := []byte{0x00, 0xFD, 0x60, 0x01}
+	vm := runHaltConfig(rombytes, Config{Platform: PlatformSCHIP})
+
+	if vm.Halted() != HaltExit || vm.v[0] != 0 {
+		t.Errorf("Expected halt incorrect, got: %s", vm.Halted())
+	}
+}
+
+func TestHaltKeyboard(t *testing.T) {
+	// no keyboard to wait on
+	rombytes := []byte{0xF0, 0x0A}
+	vm := runHalt(rombytes)
+
+	if vm.Halted() != HaltKeyboard {
+		t.Errorf("Expected halt incorrect, got: %s", vm.Halted())
+	}
+}
@@ -0,0 +1,184 @@
+package chip8
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MemoryDisplay : Display that keeps the rendered frame in memory, for headless runs and tests
+type MemoryDisplay struct {
+	Width, Height int
+	Pixels        [][]uint8 // palette index of each pixel as [y][x], 0 is background
+	Frames        int       // number of frames rendered
+}
+
+// SetResolution : Resize the frame, clearing it
+func (display *MemoryDisplay) SetResolution(width int32, height int32) {
+	display.Width, display.Height = int(width), int(height)
+	display.Pixels = make([][]uint8, height)
+	for y := range display.Pixels {
+		display.Pixels[y] = make([]uint8, width)
+	}
+}
+
+// ClearDisplay : Set every pixel to the background
+func (display *MemoryDisplay) ClearDisplay() {
+	for y := range display.Pixels {
+		for x := range display.Pixels[y] {
+			display.Pixels[y][x] = 0
+		}
+	}
+}
+
+// DrawPixel : Set the palette index of the pixel at (x, y)
+func (display *MemoryDisplay) DrawPixel(x int32, y int32, colour uint8) {
+	display.Pixels[y][x] = colour
+}
+
+// UpdateDisplay : Count the finished frame
+func (display *MemoryDisplay) UpdateDisplay() {
+	display.Frames++
+}
+
+// String : Frame as text, one line per row with "." for the background and the palette index otherwise
+func (display *MemoryDisplay) String() string {
+	var sb strings.Builder
+	for _, row := range display.Pixels {
+		for _, colour := range row {
+			if colour == 0 {
+				sb.WriteByte('.')
+			} else {
+				sb.WriteByte('0' + colour)
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// KeyEvent : Scripted key press or release at the start of a frame
+type KeyEvent struct {
+	Frame int
+	Key   uint8 // 0-F key value
+	Down  bool
+}
+
+// ParseInputScript : Parse key events of the form "60:5 120:+4 180:-4"
+// "frame:key" taps the key for one frame, "frame:+key" presses it and "frame:-key" releases it.
+// Events are separated by commas or whitespace.
+func ParseInputScript(s string) ([]KeyEvent, error) {
+	var events []KeyEvent
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	for _, field := range fields {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("bad input event: %s", field)
+		}
+		frame, err := strconv.Atoi(parts[0])
+		if err != nil || frame < 0 {
+			return nil, fmt.Errorf("bad input event frame: %s", field)
+		}
+		keyName := parts[1]
+		tap := true
+		down := true
+		if strings.HasPrefix(keyName, "+") || strings.HasPrefix(keyName, "-") {
+			tap = false
+			down = keyName[0] == '+'
+			keyName = keyName[1:]
+		}
+		key, err := strconv.ParseUint(keyName, 16, 8)
+		if err != nil || key > 0xF {
+			return nil, fmt.Errorf("bad input event key: %s", field)
+		}
+		events = append(events, KeyEvent{Frame: frame, Key: uint8(key), Down: down})
+		if tap {
+			events = append(events, KeyEvent{Frame: frame + 1, Key: uint8(key), Down: false})
+		}
+	}
+	return events, nil
+}
+
+// ScriptedKeyboard : Keyboard replaying scripted key events, for headless runs and tests
+type ScriptedKeyboard struct {
+	events  []KeyEvent
+	next    int // index of the next event to apply
+	frame   int
+	pressed [16]bool
+	presses []KeyEvent // presses not yet given to Fx0A, from the frame the wait started
+	waiting bool       // Fx0A is waiting for a press
+}
+
+// NewScriptedKeyboard : Keyboard replaying events in frame order
+func NewScriptedKeyboard(events []KeyEvent) *ScriptedKeyboard {
+	keyboard := &ScriptedKeyboard{events: append([]KeyEvent(nil), events...)}
+	sort.SliceStable(keyboard.events, func(i, j int) bool {
+		return keyboard.events[i].Frame < keyboard.events[j].Frame
+	})
+	return keyboard
+}
+
+// SetFrame : Apply the events up to and including frame, called at the start of each frame
+func (keyboard *ScriptedKeyboard) SetFrame(frame int) {
+	keyboard.frame = frame
+	if !keyboard.waiting {
+		keyboard.presses = keyboard.presses[:0]
+	}
+	keyboard.applyDue()
+}
+
+// applyDue : Apply the events up to and including the current frame
+func (keyboard *ScriptedKeyboard) applyDue() {
+	for keyboard.next < len(keyboard.events) && keyboard.events[keyboard.next].Frame <= keyboard.frame {
+		event := keyboard.events[keyboard.next]
+		keyboard.pressed[event.Key&0xF] = event.Down
+		if event.Down {
+			keyboard.presses = append(keyboard.presses, event)
+		}
+		keyboard.next++
+	}
+}
+
+// PollKeyPress : Key pressed by the script since the start of the frame Fx0A started waiting in
+// Presses scripted for later frames are left for those frames. Not running once the script has
+// no more presses to give.
+func (keyboard *ScriptedKeyboard) PollKeyPress() (uint8, bool, bool) {
+	keyboard.applyDue()
+	if !keyboard.waiting {
+		keyboard.waiting = true
+		for len(keyboard.presses) > 0 && keyboard.presses[0].Frame < keyboard.frame {
+			keyboard.presses = keyboard.presses[1:]
+		}
+	}
+	if len(keyboard.presses) > 0 {
+		key := keyboard.presses[0].Key & 0xF
+		keyboard.presses = keyboard.presses[1:]
+		keyboard.waiting = false
+		return key, true, true
+	}
+	for _, event := range keyboard.events[keyboard.next:] {
+		if event.Down {
+			return 0, false, true
+		}
+	}
+	keyboard.waiting = false
+	return 0, false, false
+}
+
+// WaitForKeyPress : Key pressed by the script by the current frame, as PollKeyPress, not running
+// when there is none
+func (keyboard *ScriptedKeyboard) WaitForKeyPress() (uint8, bool) {
+	key, pressed, _ := keyboard.PollKeyPress()
+	if !pressed {
+		keyboard.waiting = false
+	}
+	return key, pressed
+}
+
+// IsKeyPressed : Whether the key is held down by the script
+func (keyboard *ScriptedKeyboard) IsKeyPressed(key uint8) bool {
+	return keyboard.pressed[key&0xF]
+}
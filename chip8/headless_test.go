@@ -0,0 +1,103 @@
+package chip8
+
+import "testing"
+
+func TestParseInputScript(t *testing.T) {
+	events, err := ParseInputScript("60:5, 120:+a\n180:-A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []KeyEvent{{60, 5, true}, {61, 5, false}, {120, 0xA, true}, {180, 0xA, false}}
+	if len(events) != len(want) {
+		t.Fatalf("Expected events incorrect, got: %v", events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("Expected events incorrect, got: %v, want: %v", events, want)
+		}
+	}
+	for _, bad := range []string{"60", "x:5", "60:G", "60:10"} {
+		if _, err := ParseInputScript(bad); err == nil {
+			t.Errorf("ParseInputScript accepted %q", bad)
+		}
+	}
+}
+
+func TestScriptedKeyboard(t *testing.T) {
+	keyboard := NewScriptedKeyboard([]KeyEvent{{10, 4, false}, {5, 4, true}, {20, 7, true}})
+
+	keyboard.SetFrame(4)
+	if keyboard.IsKeyPressed(4) {
+		t.Errorf("Key pressed before its event")
+	}
+	keyboard.SetFrame(5)
+	if !keyboard.IsKeyPressed(4) {
+		t.Errorf("Key not pressed at its event")
+	}
+	// Fx0A gets presses from the frame it started waiting in, and waits for later ones
+	if key, pressed, running := keyboard.PollKeyPress(); key != 4 || !pressed || !running {
+		t.Errorf("Expected key incorrect, got: %x, %t, %t", key, pressed, running)
+	}
+	keyboard.SetFrame(6)
+	if _, pressed, running := keyboard.PollKeyPress(); pressed || !running {
+		t.Errorf("Expected to wait for the press at frame 20, got: %t, %t", pressed, running)
+	}
+	keyboard.SetFrame(19)
+	if _, pressed, _ := keyboard.PollKeyPress(); pressed || keyboard.IsKeyPressed(7) {
+		t.Errorf("Key pressed before its event")
+	}
+	keyboard.SetFrame(20)
+	if key, pressed, running := keyboard.PollKeyPress(); key != 7 || !pressed || !running {
+		t.Errorf("Expected key incorrect, got: %x, %t, %t", key, pressed, running)
+	}
+	if _, running := keyboard.WaitForKeyPress(); running {
+		t.Errorf("Exhausted script still running")
+	}
+}
+
+func TestScriptedKeyboardWait(t *testing.T) {
+	// Fx0A at frame 0 waits, with the timers running, until the press at frame 30
+	rombytes := []byte{0x60, 0xFF, 0xF0, 0x15, 0xF1, 0x0A, 0xF2, 0x07, 0x12, 0x08}
+	keyboard := NewScriptedKeyboard([]KeyEvent{{30, 5, true}})
+	vm := New(Config{Keyboard: keyboard})
+	vm.LoadROM(rombytes)
+	for frame := 0; frame <= 30; frame++ {
+		keyboard.SetFrame(frame)
+		if frame == 29 && vm.PC() != 0x204 {
+			t.Errorf("Expected Fx0A to wait, got PC: 0x%x", vm.PC())
+		}
+		vm.RunFrame()
+	}
+	if vm.V(1) != 5 || vm.V(2) != 0xFF-30 {
+		t.Errorf("Expected key and delay timer incorrect, got: %x, %d", vm.V(1), vm.V(2))
+	}
+}
+
+func TestMemoryDisplay(t *testing.T) {
+	// Wait for a key and draw its font character
+	rombytes := []byte{0xF0, 0x0A, 0xF0, 0x29, 0xD1, 0x15, 0x12, 0x06}
+	display := &MemoryDisplay{}
+	keyboard := NewScriptedKeyboard([]KeyEvent{{0, 1, true}})
+	vm := New(Config{Display: display, Keyboard: keyboard})
+	vm.LoadROM(rombytes)
+	for {
+		if running, _ := vm.RunFrame(); !running {
+			break
+		}
+	}
+
+	want := "..1.\n.11.\n..1.\n..1.\n.111\n"
+	got := ""
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 4; x++ {
+			got += string(".1"[display.Pixels[y][x]])
+		}
+		got += "\n"
+	}
+	if vm.Halted() != HaltSelfJump || display.Width != 64 || display.Height != 32 || got != want {
+		t.Errorf("Expected display incorrect, got: %s\n%s", vm.Halted(), got)
+	}
+	if display.Frames != 1 {
+		t.Errorf("Expected frames incorrect, got: %d", display.Frames)
+	}
+}
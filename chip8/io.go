@@ -0,0 +1,54 @@
+package chip8
+
+// Display : Output for the screen, coordinates are in CHIP-8 pixels
+type Display interface {
+	ClearDisplay()
+	UpdateDisplay()
+	DrawPixel(x int32, y int32, colour uint8) // colour is a 1-3 palette index
+	SetResolution(width int32, height int32)
+}
+
+// Keyboard : Input for the 16 key hexadecimal keypad
+type Keyboard interface {
+	WaitForKeyPress() (uint8, bool) // key, running
+	IsKeyPressed(key uint8) bool    // argument is 0-F key value
+}
+
+// FrameKeyboard : Keyboard read once per frame, so a run depends only on the keys held each frame
+// Machines call SampleKeys before the first instruction of each frame, with the number of frames
+// run so far, and IsKeyPressed then reports the keys held at that point.
+type FrameKeyboard interface {
+	Keyboard
+	SampleKeys(frame uint64)
+}
+
+// KeyPoller : Keyboard answering Fx0A without blocking, e.g. one driven by frames
+// While PollKeyPress reports no key pressed the Machine runs Fx0A again, with the timers ticking,
+// and once it reports the keyboard isn't running the Machine halts with HaltKeyboard.
+type KeyPoller interface {
+	Keyboard
+	PollKeyPress() (key uint8, pressed bool, running bool)
+}
+
+// pollKey : Poll keyboard for a key press, waiting for one on keyboards that can't be polled
+func pollKey(keyboard Keyboard) (uint8, bool, bool) {
+	if poller, ok := keyboard.(KeyPoller); ok {
+		return poller.PollKeyPress()
+	}
+	key, running := keyboard.WaitForKeyPress()
+	return key, running, running
+}
+
+// Audio : Output for the sound timer
+type Audio interface {
+	SoundTick(playing bool) // called on every timer tick, playing while the sound timer is above 0
+}
+
+// PatternAudio : Optional interface for Audio that plays XO-CHIP audio patterns
+// On the xo-chip platform, once F002 has loaded a pattern that isn't all zeros, PatternTick is
+// called on every timer tick instead of SoundTick, with the pattern's playback rate in bits per
+// second set by Fx3A.
+type PatternAudio interface {
+	Audio
+	PatternTick(playing bool, pattern [16]uint8, rate float64)
+}
@@ -0,0 +1,301 @@
+package chip8
+
+import (
+	"fmt"
+	"strings"
+)
+
+// listing : Code and data of a ROM found by recursive descent from 0x200, for DisassembleROM
+type listing struct {
+	rom      []byte
+	size     map[uint16]int    // size of the instruction at each code address
+	code     []bool            // byte is part of an instruction, by offset into rom
+	labels   map[uint16]string // branch targets and data referenced by I
+	sprites  map[uint16]int    // sprite data drawn from an address, and its size in bytes
+	comments map[uint16]string // idioms found at instructions
+	loops    map[uint16]bool   // targets of backward jumps
+}
+
+// DisassembleROM : Annotated assembly source for a ROM loaded at 0x200
+// Code is found by following jumps, calls and skips from 0x200, and the rest is written as data,
+// with sprites in binary. Branch targets and data loaded into I get labels, and common idioms
+// such as loops, BCD conversion and key waits are commented. Assemble turns the source back
+// into the same bytes.
+func DisassembleROM(rom []byte) string {
+	l := &listing{
+		rom:      rom,
+		size:     make(map[uint16]int),
+		code:     make([]bool, len(rom)),
+		labels:   make(map[uint16]string),
+		sprites:  make(map[uint16]int),
+		comments: make(map[uint16]string),
+		loops:    make(map[uint16]bool),
+	}
+	l.trace()
+	l.annotate()
+	return l.String()
+}
+
+func (l *listing) inROM(addr int) bool { return addr >= 0x200 && addr < 0x200+len(l.rom) }
+
+// opcode : Word at addr, 0 past the end of the ROM
+func (l *listing) opcode(addr uint16) uint16 {
+	if !l.inROM(int(addr)) || !l.inROM(int(addr)+1) {
+		return 0
+	}
+	return uint16(l.rom[addr-0x200])<<8 | uint16(l.rom[addr-0x200+1])
+}
+
+// decode : Instruction at addr and its size, false if it isn't a known instruction within the ROM
+func (l *listing) decode(addr uint16) (string, int, bool) {
+	if !l.inROM(int(addr)) {
+		return "", 0, false
+	}
+	text, size := Disassemble(l.rom[addr-0x200:])
+	if size < 2 || strings.HasPrefix(text, "DW ") || strings.HasPrefix(text, "SYS ") || !l.inROM(int(addr)+size-1) {
+		return "", 0, false
+	}
+	return text, size, true
+}
+
+// trace : Follow the flow of control from 0x200, marking instructions as code
+func (l *listing) trace() {
+	l.labels[0x200] = "start"
+	pending := []uint16{0x200}
+	for len(pending) > 0 {
+		addr := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for {
+			if _, done := l.size[addr]; done {
+				break
+			}
+			_, size, ok := l.decode(addr)
+			if !ok || l.overlaps(addr, size) {
+				break
+			}
+			l.size[addr] = size
+			for i := 0; i < size; i++ {
+				l.code[int(addr)-0x200+i] = true
+			}
+
+			opcode := l.opcode(addr)
+			nnn := opcode & 0xFFF
+			next := addr + uint16(size)
+			switch {
+			case opcode == 0x00EE || opcode == 0x00FD:
+				next = 0
+			case opcode&0xF000 == 0x1000:
+				if nnn != addr {
+					l.label(nnn, "label")
+					pending = append(pending, nnn)
+				}
+				next = 0
+			case opcode&0xF000 == 0x2000:
+				l.label(nnn, "sub")
+				pending = append(pending, nnn)
+			case opcode&0xF000 == 0xB000:
+				// jump tables are usually a run of jumps
+				l.label(nnn, "table")
+				for entry := nnn; l.opcode(entry)&0xF000 == 0x1000; entry += 2 {
+					pending = append(pending, entry)
+				}
+				next = 0
+			case opcode&0xF000 == 0xA000:
+				l.dataLabel(addr, nnn)
+			case opcode == 0xF000:
+				l.dataLabel(addr, uint16(l.rom[addr-0x200+2])<<8|uint16(l.rom[addr-0x200+3]))
+			case isSkip(opcode):
+				if _, skipSize, ok := l.decode(next); ok {
+					pending = append(pending, next+uint16(skipSize))
+				}
+			}
+			if next == 0 {
+				break
+			}
+			addr = next
+		}
+	}
+	// labels are only kept where they can be written, at the start of an instruction or in data
+	for addr := range l.labels {
+		if _, isCode := l.size[addr]; !isCode && l.inROM(int(addr)) && l.code[addr-0x200] {
+			delete(l.labels, addr)
+		}
+	}
+}
+
+func isSkip(opcode uint16) bool {
+	switch opcode & 0xF000 {
+	case 0x3000, 0x4000:
+		return true
+	case 0x5000, 0x9000:
+		return opcode&0xF == 0
+	case 0xE000:
+		return opcode&0xFF == 0x9E || opcode&0xFF == 0xA1
+	}
+	return false
+}
+
+// overlaps : Whether an instruction at addr would overlap one already found
+func (l *listing) overlaps(addr uint16, size int) bool {
+	for i := 0; i < size; i++ {
+		if l.code[int(addr)-0x200+i] {
+			return true
+		}
+	}
+	return false
+}
+
+// label : Name a target in the ROM, keeping the first name given
+func (l *listing) label(addr uint16, kind string) {
+	if !l.inROM(int(addr)) {
+		return
+	}
+	if _, ok := l.labels[addr]; !ok {
+		l.labels[addr] = fmt.Sprintf("%s_%03X", kind, addr)
+	}
+}
+
+// dataLabel : Label data loaded into I at addr, as a sprite if it is drawn before I changes
+func (l *listing) dataLabel(at, data uint16) {
+	if !l.inROM(int(data)) {
+		return
+	}
+	for addr, i := at+2, 0; i < 8; i++ {
+		opcode := l.opcode(addr)
+		switch {
+		case opcode&0xF000 == 0xD000:
+			size := int(opcode & 0xF)
+			if size == 0 {
+				size = 32
+			}
+			if size > l.sprites[data] {
+				l.sprites[data] = size
+			}
+			l.label(data, "sprite")
+			return
+		case opcode&0xF000 == 0xA000, opcode == 0xF000, opcode&0xF0FF == 0xF01E, opcode&0xF0FF == 0xF029,
+			opcode&0xF000 == 0x1000, opcode&0xF000 == 0xB000, opcode == 0x00EE:
+			i = 8
+		}
+		if opcode == 0xF000 {
+			addr += 2
+		}
+		addr += 2
+	}
+	l.label(data, "data")
+}
+
+// annotate : Comment idioms in the code
+func (l *listing) annotate() {
+	for addr := range l.size {
+		opcode := l.opcode(addr)
+		x := opcode >> 8 & 0xF
+		nnn := opcode & 0xFFF
+		switch {
+		case opcode&0xF000 == 0x1000 && nnn == addr:
+			l.comments[addr] = "halt, jumping to itself"
+		case opcode&0xF000 == 0x1000 && nnn < addr:
+			l.loops[nnn] = true
+		case opcode&0xF0FF == 0xF00A:
+			l.comments[addr] = fmt.Sprintf("wait for a key press, into V%X", x)
+		case opcode&0xF0FF == 0xF033:
+			l.comments[addr] = fmt.Sprintf("BCD of V%X: hundreds, tens and ones digits at I", x)
+			if next := l.opcode(addr + 2); next&0xF0FF == 0xF065 && next>>8&0xF >= 2 {
+				l.comments[addr+2] = "load the BCD digits into V0-V2"
+			}
+		case opcode&0xF0FF == 0xF029:
+			l.comments[addr] = fmt.Sprintf("font sprite of the digit in V%X", x)
+		case opcode&0xF0FF == 0xF007:
+			// LD Vx, DT; SE Vx, 0; JP back
+			if l.opcode(addr+2) == 0x3000|x<<8 && l.opcode(addr+4) == 0x1000|addr {
+				l.comments[addr] = "wait for the delay timer to reach 0"
+			}
+		case opcode&0xF0FF == 0xE09E || opcode&0xF0FF == 0xE0A1:
+			// SKP Vx; JP back
+			if l.opcode(addr+2) == 0x1000|addr {
+				if opcode&0xFF == 0x9E {
+					l.comments[addr] = fmt.Sprintf("wait for key V%X to be pressed", x)
+				} else {
+					l.comments[addr] = fmt.Sprintf("wait for key V%X to be released", x)
+				}
+			}
+		}
+	}
+}
+
+// String : Assembly source of the listing
+func (l *listing) String() string {
+	var sb strings.Builder
+	sb.WriteString("; Disassembled by chip8go\n")
+	end := 0x200 + len(l.rom)
+	for addr := 0x200; addr < end; {
+		if label, ok := l.labels[uint16(addr)]; ok {
+			sb.WriteString("\n" + label + ":")
+			if l.loops[uint16(addr)] {
+				sb.WriteString("  ; loop")
+			}
+			sb.WriteString("\n")
+		}
+		if size, ok := l.size[uint16(addr)]; ok {
+			l.writeInstruction(&sb, uint16(addr))
+			addr += size
+			continue
+		}
+		addr = l.writeData(&sb, addr)
+	}
+	return sb.String()
+}
+
+func (l *listing) writeInstruction(sb *strings.Builder, addr uint16) {
+	text, _ := Disassemble(l.rom[addr-0x200:])
+	opcode := l.opcode(addr)
+	target, width := opcode&0xFFF, 3
+	switch {
+	case opcode == 0xF000:
+		target, width = uint16(l.rom[addr-0x200+2])<<8|uint16(l.rom[addr-0x200+3]), 4
+	case opcode&0xF000 == 0x1000, opcode&0xF000 == 0x2000, opcode&0xF000 == 0xA000, opcode&0xF000 == 0xB000:
+	default:
+		width = 0
+	}
+	if label, ok := l.labels[target]; ok && width > 0 {
+		text = strings.Replace(text, fmt.Sprintf("0x%0*X", width, target), label, 1)
+	}
+	if comment, ok := l.comments[addr]; ok {
+		fmt.Fprintf(sb, "    %-22s ; %s\n", text, comment)
+		return
+	}
+	fmt.Fprintf(sb, "    %s\n", text)
+}
+
+// writeData : Write the data from addr up to the next code or label, returns where it stopped
+// Sprites are written a row at a time in binary, other data 8 bytes to a line.
+func (l *listing) writeData(sb *strings.Builder, addr int) int {
+	end := 0x200 + len(l.rom)
+	var values []string
+	flush := func() {
+		if len(values) > 0 {
+			sb.WriteString("    DB " + strings.Join(values, ", ") + "\n")
+			values = nil
+		}
+	}
+	spriteEnd := 0
+	for start := addr; addr < end && !l.code[addr-0x200]; addr++ {
+		if _, ok := l.labels[uint16(addr)]; ok && addr != start {
+			break
+		}
+		if size, ok := l.sprites[uint16(addr)]; ok {
+			flush()
+			spriteEnd = addr + size
+		}
+		if addr < spriteEnd {
+			fmt.Fprintf(sb, "    DB 0b%08b\n", l.rom[addr-0x200])
+			continue
+		}
+		values = append(values, fmt.Sprintf("0x%02X", l.rom[addr-0x200]))
+		if len(values) == 8 {
+			flush()
+		}
+	}
+	flush()
+	return addr
+}
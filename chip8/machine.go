@@ -0,0 +1,483 @@
+package chip8
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Platforms supported by Config.Platform
+const (
+	PlatformChip8  = "chip-8"
+	PlatformSCHIP  = "schip"
+	PlatformXOChip = "xo-chip"
+)
+
+// Stack depths for Config.StackDepth
+const (
+	StackDepthVIP   = 12 // COSMAC VIP interpreter
+	StackDepthSCHIP = 16 // Super CHIP-8, the default
+	StackUnlimited  = -1
+)
+
+// Config : Options for a Machine, zero values use the defaults
+type Config struct {
+	Platform       string // PlatformChip8 (default), PlatformSCHIP or PlatformXOChip
+	VIPHires       string // COSMAC VIP two-page 64x64 mode: auto (default), on, off
+	Quirks         Quirks
+	WrapX          string                    // on, off, clip, error (default: from Quirks.Clip)
+	WrapY          string                    // on, off, clip, error (default: from Quirks.Clip)
+	ClockSpeed     int                       // instructions per second (default: 1300)
+	TimerSpeed     int                       // timer ticks and frames per second (default: 60)
+	CyclesPerFrame int                       // instructions per frame (default: ClockSpeed / TimerSpeed)
+	ScreenBuffer   int                       // number of frames merged by Render to prevent flickering (default: 1)
+	FaultPolicy    map[FaultKind]FaultPolicy // action for each runtime fault (default: PolicyHalt)
+	StackDepth     int                       // maximum nested calls: StackDepthVIP, StackDepthSCHIP (default) or StackUnlimited
+	Seed           int64                     // seed for Cxkk random numbers, repeated by each Reset (default: from the time)
+	Display        Display
+	Keyboard       Keyboard // nil for no keys pressed, Fx0A then halts with HaltKeyboard
+	Audio          Audio
+}
+
+// Machine : CHIP-8 virtual machine - holds all memory and registers
+type Machine struct {
+	config                 Config
+	rom                    []byte
+	pc, i, opcode, sp      uint16
+	v                      [16]uint8
+	memory                 [0x10000]uint8 // 64 KiB for XO-CHIP, other platforms use the first 4 KiB
+	memSize                int
+	screen                 [2][64][16]uint8 // XO-CHIP bitplanes, bitmap up to 128x64, low-res uses the top-left 64x32
+	width, height          uint8
+	hires                  bool
+	planes                 uint8     // XO-CHIP bitplane selection mask
+	rpl                    [16]uint8 // Super CHIP-8 RPL user flags
+	pattern                [16]uint8 // XO-CHIP 1-bit audio pattern, 128 samples
+	pitch                  uint8     // XO-CHIP audio pattern playback pitch
+	platform               string
+	vipHires               string // COSMAC VIP two-page 64x64 mode: auto, on, off
+	delayTimer, soundTimer uint8
+	stack                  []uint16
+	stackDepth             int // maximum nested calls, -1 for unlimited
+	drawflag               bool
+	wrapX                  string // on, off, clip, error
+	wrapY                  string // on, off, clip, error
+	quirks                 Quirks
+	vblankWait             bool // waiting for the next timer tick after Dxyn with the DisplayWait quirk
+	cyclesPerFrame         int
+	timerSpeed             int
+	screenBuffer           uint8
+	screenarray            [][2][64][16]uint8 // previous frames merged by Render
+	displayWidth           uint8              // resolution last set on the display
+	displayHeight          uint8
+	dirty                  bool   // screen changed since the last Render
+	fault                  *Fault // fault that halted the machine
+	halt                   HaltReason
+	cycles                 uint64 // instructions executed
+	frames                 uint64 // timer ticks
+	frameCycle             int    // instructions executed in the current frame
+	keysFrame              uint64 // frames+1 when a FrameKeyboard last sampled the keys, 0 for not yet
+	rng                    uint64 // Cxkk random number generator state
+	spin                   spinLoop
+	debugger               *Debugger
+	memoryHook             MemoryHook // nil unless memory accesses are being watched
+}
+
+// MemoryHook : Called before an instruction reads or writes size bytes of memory from addr
+// PC is still the address of the instruction during the call. Instruction fetches are not reported.
+type MemoryHook func(addr uint16, size int, write bool)
+
+// New : Create a Machine with no ROM loaded
+func New(config Config) *Machine {
+	if config.Platform == "" {
+		config.Platform = PlatformChip8
+	}
+	if config.VIPHires == "" {
+		config.VIPHires = "auto"
+	}
+	if config.ClockSpeed == 0 {
+		config.ClockSpeed = 1300
+	}
+	if config.TimerSpeed == 0 {
+		config.TimerSpeed = 60
+	}
+	if config.CyclesPerFrame == 0 {
+		config.CyclesPerFrame = config.ClockSpeed / config.TimerSpeed
+		if config.CyclesPerFrame < 1 {
+			config.CyclesPerFrame = 1
+		}
+	}
+	if config.ScreenBuffer == 0 {
+		config.ScreenBuffer = 1
+	}
+	if config.StackDepth == 0 {
+		config.StackDepth = StackDepthSCHIP
+	}
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	vm := &Machine{config: config}
+	vm.Reset()
+	return vm
+}
+
+// LoadROM : Load a ROM at 0x200 and reset the machine
+func (vm *Machine) LoadROM(rom []byte) error {
+	if 0x200+len(rom) > vm.memSize {
+		return fmt.Errorf("ROM too large: %d bytes, maximum is %d bytes", len(rom), vm.memSize-0x200)
+	}
+	vm.rom = append([]byte(nil), rom...)
+	vm.Reset()
+	return nil
+}
+
+// Reset : Reset the machine to its initial state, keeping the loaded ROM
+func (vm *Machine) Reset() {
+	*vm = Machine{config: vm.config, rom: vm.rom, debugger: vm.debugger, memoryHook: vm.memoryHook}
+	config := vm.config
+
+	vm.platform = config.Platform
+	vm.memSize = 4096
+	if vm.platform == PlatformXOChip {
+		vm.memSize = 0x10000
+	}
+	vm.initialiseFont()
+	vm.loadROM(vm.rom)
+	vm.pc = 0x200
+	vm.drawflag = false
+	vm.width = 64
+	vm.height = 32
+	vm.planes = 1
+	vm.pitch = 64
+	vm.vipHires = config.VIPHires
+	if vm.vipHires == "on" {
+		vm.setVIPHires()
+	}
+	vm.quirks = config.Quirks
+	// wrapping follows the clip quirk unless set explicitly
+	vm.wrapX = config.WrapX
+	vm.wrapY = config.WrapY
+	if vm.wrapX == "" || vm.wrapY == "" {
+		wrap := "on"
+		if vm.quirks.Clip {
+			wrap = "clip"
+		}
+		if vm.wrapX == "" {
+			vm.wrapX = wrap
+		}
+		if vm.wrapY == "" {
+			vm.wrapY = wrap
+		}
+	}
+	vm.stackDepth = config.StackDepth
+	vm.cyclesPerFrame = config.CyclesPerFrame
+	vm.timerSpeed = config.TimerSpeed
+	vm.screenBuffer = uint8(config.ScreenBuffer)
+	vm.screenarray = make([][2][64][16]uint8, vm.screenBuffer)
+	vm.rng = uint64(config.Seed)
+}
+
+// Seed : Seed for Cxkk random numbers, the machine draws the same numbers after every Reset
+func (vm *Machine) Seed() int64 { return vm.config.Seed }
+
+// random : Next number from the splitmix64 generator, which keeps its state in a single word for save states
+func (vm *Machine) random() uint64 {
+	vm.rng += 0x9E3779B97F4A7C15
+	z := vm.rng
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	return z ^ z>>31
+}
+
+func (vm *Machine) loadROM(rombytes []byte) {
+	for i, byt := range rombytes {
+		vm.memory[0x200+i] = byt
+	}
+}
+
+// Step : Execute a single instruction, returns false once the program has halted (see Halted)
+// Runtime faults are handled according to Config.FaultPolicy, halting and breaking faults are returned.
+// With a Debugger attached, a *Break is returned instead of running an instruction that hits a breakpoint,
+// or after running an instruction that hits a watchpoint.
+func (vm *Machine) Step() (bool, error) {
+	if vm.halt != NotHalted {
+		return false, vm.Fault()
+	}
+	if vm.vblankWait && (vm.debugger == nil || !vm.debugger.paused) {
+		// no instruction runs while waiting, so breakpoints aren't checked until the wait ends
+		vm.drawflag = false
+		return true, nil
+	}
+	if vm.debugger != nil {
+		if brk := vm.debugger.check(); brk != nil {
+			return true, brk
+		}
+	}
+	running, fault := vm.execute()
+	vm.cycles++
+	if vm.drawflag {
+		vm.dirty = true
+	}
+	if fault != nil {
+		switch vm.config.FaultPolicy[fault.Kind] {
+		case PolicyIgnore:
+			vm.pc += vm.instructionLength(vm.pc)
+		case PolicyLog:
+			log.Println(fault)
+			vm.pc += vm.instructionLength(vm.pc)
+		case PolicyBreak:
+			if vm.debugger != nil {
+				vm.debugger.paused = true
+				vm.debugger.stop = nil
+			}
+			return true, fault
+		default:
+			vm.fault = fault
+			vm.halt = HaltFault
+			return false, fault
+		}
+	}
+	if running && vm.spinning() {
+		vm.halt = HaltSpinLoop
+		running = false
+	}
+	if vm.debugger != nil {
+		if brk := vm.debugger.watched(); brk != nil {
+			return running, brk
+		}
+	}
+	return running, nil
+}
+
+// Cycles : Number of instructions executed since the last Reset
+func (vm *Machine) Cycles() uint64 { return vm.cycles }
+
+// Frames : Number of frames run since the last Reset, counted by timer ticks
+func (vm *Machine) Frames() uint64 { return vm.frames }
+
+// Halted : How the program stopped, NotHalted while it is running
+func (vm *Machine) Halted() HaltReason { return vm.halt }
+
+// Fault : Fault that halted the machine, nil if it has not halted on a fault
+func (vm *Machine) Fault() error {
+	if vm.fault == nil {
+		return nil
+	}
+	return vm.fault
+}
+
+// TickTimers : Decrement the delay and sound timers, called TimerSpeed times per second
+func (vm *Machine) TickTimers() {
+	vm.frames++
+	vm.vblankWait = false
+	if vm.delayTimer > 0 {
+		vm.delayTimer--
+	}
+	if patternAudio, ok := vm.config.Audio.(PatternAudio); ok && vm.platform == PlatformXOChip && vm.pattern != [16]uint8{} {
+		patternAudio.PatternTick(vm.soundTimer > 0, vm.pattern, vm.playbackRate())
+	} else if vm.config.Audio != nil {
+		vm.config.Audio.SoundTick(vm.soundTimer > 0)
+	}
+	if vm.soundTimer > 0 {
+		vm.soundTimer--
+	}
+}
+
+// RunFrame : Execute one frame of CyclesPerFrame instructions, then tick the timers once
+// and render the screen if it changed. Returns false once the program has stopped.
+// The frame ends early on a fault returned by Step.
+func (vm *Machine) RunFrame() (bool, error) {
+	running, err := vm.SkipFrame()
+	if vm.dirty {
+		vm.Render()
+	}
+	return running, err
+}
+
+// SkipFrame : Execute one frame like RunFrame without rendering, to catch up when running behind
+// Changes to the screen are rendered by the next RunFrame. A frame that ended early on a fault
+// or breakpoint is finished by the next call.
+func (vm *Machine) SkipFrame() (bool, error) {
+	for {
+		running, err := vm.stepInFrame()
+		if !running || err != nil || vm.frameCycle == 0 {
+			return running, err
+		}
+	}
+}
+
+// stepInFrame : Execute a single instruction of the current frame, ticking the timers once the frame is complete
+func (vm *Machine) stepInFrame() (bool, error) {
+	if vm.frameCycle == 0 && vm.keysFrame != vm.frames+1 {
+		if keyboard, ok := vm.config.Keyboard.(FrameKeyboard); ok {
+			keyboard.SampleKeys(vm.frames)
+		}
+		vm.keysFrame = vm.frames + 1
+	}
+	running, err := vm.Step()
+	if brk, ok := err.(*Break); !running || err != nil && !(ok && brk.Access != nil) {
+		return running, err
+	}
+	vm.frameCycle++
+	if vm.frameCycle >= vm.cyclesPerFrame {
+		vm.frameCycle = 0
+		vm.TickTimers()
+	}
+	return true, err
+}
+
+// TimerSpeed : Timer ticks and frames per second
+func (vm *Machine) TimerSpeed() int { return vm.timerSpeed }
+
+// Render : Draw the screen to the Display, merged with the previous ScreenBuffer frames
+func (vm *Machine) Render() {
+	display := vm.config.Display
+	vm.dirty = false
+	if display == nil {
+		return
+	}
+	var andscreen [2][64][16]uint8 // bitplanes up to 128x64
+
+	if vm.width != vm.displayWidth || vm.height != vm.displayHeight {
+		vm.displayWidth, vm.displayHeight = vm.width, vm.height
+		display.SetResolution(int32(vm.width), int32(vm.height))
+	}
+	display.ClearDisplay()
+	for yp := 0; yp < int(vm.height); yp++ {
+		for xb := 0; xb < int(vm.width/8); xb++ {
+			for p := 0; p < 2; p++ {
+				for z := uint8(0); z < vm.screenBuffer; z++ {
+					andscreen[p][yp][xb] = vm.screenarray[z][p][yp][xb] | vm.screen[p][yp][xb]
+				}
+			}
+			for xp := 0; xp < 8; xp++ {
+				// colour index is the value of the bitplanes at this pixel
+				colour := andscreen[0][yp][xb]>>uint8(7-xp)&1 | (andscreen[1][yp][xb]>>uint8(7-xp)&1)<<1
+				if colour != 0 {
+					display.DrawPixel(int32(8)*int32(xb)+int32(xp), int32(yp), colour)
+				}
+			}
+		}
+	}
+	display.UpdateDisplay()
+	vm.screenarray[0] = vm.screen
+	for z := vm.screenBuffer - 1; z > 0; z-- {
+		vm.screenarray[z] = vm.screenarray[z-1]
+	}
+}
+
+func (vm *Machine) keyPressed(key uint8) bool {
+	if vm.config.Keyboard == nil {
+		return false
+	}
+	return vm.config.Keyboard.IsKeyPressed(key)
+}
+
+// waitForKey : Key pressed for Fx0A, polling keyboards that can be polled: key, pressed, running
+func (vm *Machine) waitForKey() (uint8, bool, bool) {
+	if vm.config.Keyboard == nil {
+		return 0, false, false
+	}
+	return pollKey(vm.config.Keyboard)
+}
+
+// PC : Program counter
+func (vm *Machine) PC() uint16 { return vm.pc }
+
+// SetPC : Set the program counter
+func (vm *Machine) SetPC(pc uint16) { vm.pc = pc }
+
+// I : Index register
+func (vm *Machine) I() uint16 { return vm.i }
+
+// SetI : Set the index register
+func (vm *Machine) SetI(i uint16) { vm.i = i }
+
+// V : Value of register Vx, x is 0-F
+func (vm *Machine) V(x uint8) uint8 { return vm.v[x&0xF] }
+
+// SetV : Set register Vx, x is 0-F
+func (vm *Machine) SetV(x uint8, value uint8) { vm.v[x&0xF] = value }
+
+// SP : Stack pointer, the number of return addresses on the stack
+func (vm *Machine) SP() uint16 { return vm.sp }
+
+// Stack : Copy of the return addresses on the stack, oldest first
+func (vm *Machine) Stack() []uint16 {
+	return append([]uint16(nil), vm.stack[:vm.sp]...)
+}
+
+// Opcode : Last instruction fetched
+func (vm *Machine) Opcode() uint16 { return vm.opcode }
+
+// DelayTimer : Delay timer value
+func (vm *Machine) DelayTimer() uint8 { return vm.delayTimer }
+
+// SetDelayTimer : Set the delay timer
+func (vm *Machine) SetDelayTimer(value uint8) { vm.delayTimer = value }
+
+// SoundTimer : Sound timer value
+func (vm *Machine) SoundTimer() uint8 { return vm.soundTimer }
+
+// SetSoundTimer : Set the sound timer
+func (vm *Machine) SetSoundTimer(value uint8) { vm.soundTimer = value }
+
+// MemorySize : Size of the addressable memory in bytes, 4 KiB or 64 KiB for XO-CHIP
+func (vm *Machine) MemorySize() int { return vm.memSize }
+
+// ReadMemory : Byte at addr
+func (vm *Machine) ReadMemory(addr uint16) uint8 { return vm.memory[addr] }
+
+// WriteMemory : Set the byte at addr
+func (vm *Machine) WriteMemory(addr uint16, value uint8) { vm.memory[addr] = value }
+
+// SetMemoryHook : Report the memory accesses of Dxyn, Fx33, Fx55, Fx65 and the XO-CHIP 5xy2, 5xy3
+// and F002 instructions to hook, nil to stop. Without a hook the accesses cost a nil check.
+func (vm *Machine) SetMemoryHook(hook MemoryHook) { vm.memoryHook = hook }
+
+// Memory : Copy of the addressable memory
+func (vm *Machine) Memory() []uint8 {
+	return append([]uint8(nil), vm.memory[:vm.memSize]...)
+}
+
+// Resolution : Current screen width and height in pixels
+func (vm *Machine) Resolution() (int, int) { return int(vm.width), int(vm.height) }
+
+// Pixel : Palette index (0-3) of the pixel at (x, y), the value of the bitplanes
+func (vm *Machine) Pixel(x int, y int) uint8 {
+	mask := uint8(0x80) >> uint(x%8)
+	var colour uint8
+	for p := 0; p < 2; p++ {
+		if vm.screen[p][y][x/8]&mask != 0 {
+			colour |= 1 << uint(p)
+		}
+	}
+	return colour
+}
+
+// Drawn : Whether the last instruction changed the screen
+func (vm *Machine) Drawn() bool { return vm.drawflag }
+
+// Platform : Platform being emulated
+func (vm *Machine) Platform() string { return vm.platform }
+
+// Quirks : Quirks in use
+func (vm *Machine) Quirks() Quirks { return vm.quirks }
+
+// PrintState : Print the registers, memory, screen and stack for debugging
+func (vm *Machine) PrintState() {
+	fmt.Printf("PC: 0x%x\n", vm.pc)
+	fmt.Printf("I: 0x%x\n", vm.i)
+	fmt.Printf("Opcode: 0x%x\n", vm.opcode)
+	fmt.Println("Memory:")
+	fmt.Println(vm.memory[:vm.memSize])
+	fmt.Println("V:")
+	fmt.Println(vm.v)
+	fmt.Println("Screen:")
+	fmt.Println(vm.screen)
+	fmt.Printf("DT: %d\n", vm.delayTimer)
+	fmt.Printf("ST: %d\n", vm.soundTimer)
+	fmt.Printf("SP: %d\n", vm.sp)
+	fmt.Println("Stack:")
+	fmt.Println(vm.stack)
+}
@@ -0,0 +1,1095 @@
+package chip8
+
+import "testing"
+import "fmt"
+import "errors"
+
+// padROM : ROM padded to end at addr, for programs that jump past their last instruction to halt there
+func padROM(rombytes []byte, addr int) []byte {
+	return append(rombytes, make([]byte, addr-0x200-len(rombytes))...)
+}
+
+// runVMCycles : Instruction budget of runVM, for programs that never halt
+const runVMCycles = 10000
+
+// runVM : Run a ROM without a display or keyboard until it halts or the instruction budget runs out
+// The ROM is followed by two jumps to themselves, so the program halts once it runs or skips off the end.
+func runVM(rombytes []byte, config Config) *Machine {
+	vm := New(config)
+	end := 0x200 + len(rombytes)
+	halts := []byte{byte(0x10 | end>>8), byte(end), byte(0x10 | (end+2)>>8), byte(end + 2)}
+	if err := vm.LoadROM(append(rombytes[:len(rombytes):len(rombytes)], halts...)); err != nil {
+		panic(err)
+	}
+	for c := 1; c <= runVMCycles; c++ {
+		running, err := vm.Step()
+		if !running || err != nil || vm.Halted() != NotHalted {
+			return vm
+		}
+		if c%vm.cyclesPerFrame == 0 {
+			vm.TickTimers()
+		}
+	}
+	return vm
+}
+
+func returnVM(rombytes []byte) *Machine {
+	return runVM(rombytes, Config{Platform: PlatformChip8, VIPHires: "auto", Quirks: DefaultQuirks, WrapX: "on", WrapY: "on"})
+}
+
+// returnSCHIPVM : Run a ROM using Super CHIP-8 instructions, as returnVM
+func returnSCHIPVM(rombytes []byte) *Machine {
+	return runVM(rombytes, Config{Platform: PlatformSCHIP, VIPHires: "auto", Quirks: DefaultQuirks, WrapX: "on", WrapY: "on"})
+}
+
+func Test00E0(t *testing.T) {
+	rombytes := []byte{0x60, 0x08, 0xA0, 0x55, 0xD0, 0x05, 0x00, 0xE0}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0x055) {
+		t.Errorf("RESET screen instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x055))
+	}
+	if vm.v != [16]uint8{8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.screen[0][0] != [16]uint8{0, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][1] != [16]uint8{0, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][2] != [16]uint8{0, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][3] != [16]uint8{0, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][4] != [16]uint8{0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected screen incorrect, got: %s\nfont memory: %s",
+			fmt.Sprint(vm.screen[0]),
+			fmt.Sprint(vm.memory[0x050:0x200]))
+	}
+}
+
+func Test00EE(t *testing.T) {
+	rombytes := []byte{0xA0, 0x05,
+		0x60, 0x03,
+		0x22, 0x0A,
+		0x61, 0x02,
+		0x12, 0x0E,
+		0xF0, 0x1E,
+		0x00, 0xEE,
+		0x00, 0x00}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0x008) {
+		t.Errorf("DRAW instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x008))
+	}
+	if vm.v != [16]uint8{3, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.pc != uint16(0x210) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x210))
+	}
+}
+
+func Test1nnn(t *testing.T) {
+	rombytes := padROM([]byte{0x13, 0x10}, 0x310)
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("JMP instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.pc != uint16(0x0310) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x0310))
+	}
+}
+
+func Test2nnn(t *testing.T) {
+	rombytes := padROM([]byte{0x23, 0xe6}, 0x3e6)
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("CALL instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.pc != uint16(0x03e6) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x03e6))
+	}
+	if vm.sp != uint16(1) {
+		t.Errorf("Expected stack pointer incorrect, got: %d, want: %d.", vm.pc, uint16(1))
+	}
+	if len(vm.stack) != 1 || vm.stack[0] != 0x200 {
+		t.Errorf("Expected stack incorrect, got: %s", fmt.Sprint(vm.stack))
+	}
+}
+
+func Test3xkk(t *testing.T) {
+	rombytes := []byte{0x66, 0x04, 0x36, 0x04}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SE instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x04, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.pc != uint16(0x204+2) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x204+2))
+	}
+}
+
+func Test3xkk_noskip(t *testing.T) {
+	rombytes := []byte{0x66, 0x04, 0x36, 0x01}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SE instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x04, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.pc != uint16(0x204) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x204))
+	}
+}
+
+func Test4xkk(t *testing.T) {
+	rombytes := []byte{0x66, 0x04, 0x46, 0x01}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SE instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x04, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.pc != uint16(0x204+2) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x204+2))
+	}
+}
+
+func Test4xkk_noskip(t *testing.T) {
+	rombytes := []byte{0x66, 0x04, 0x46, 0x04}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SE instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x04, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.pc != uint16(0x204) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x204))
+	}
+}
+
+func Test5xy0(t *testing.T) {
+	rombytes := []byte{0x66, 0x04, 0x67, 0x04, 0x56, 0x70}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SE instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x04, 0x04, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.pc != uint16(0x206+2) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x206+2))
+	}
+}
+
+func Test5xy0_noskip(t *testing.T) {
+	rombytes := []byte{0x66, 0x04, 0x67, 0x05, 0x56, 0x70}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SE instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x04, 0x05, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.pc != uint16(0x206) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x206))
+	}
+}
+
+func Test6xkk(t *testing.T) {
+	// LOAD Vx
+	rombytes := []byte{0x66, 0x04}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("LOAD instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x04, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test7xkk(t *testing.T) {
+	// ADD Vx
+	rombytes := []byte{0x66, 0x04, 0x76, 0x02}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("ADD instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x06, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test7xkk_overflow(t *testing.T) {
+	// ADD Vx
+	rombytes := []byte{0x66, 0x04, 0x76, 0xFF}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("ADD instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x03, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy0(t *testing.T) {
+	// LD Vx, Vy
+	rombytes := []byte{0x60, 0x55, 0x81, 0x00}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("LD instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0x55, 0x55, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy1(t *testing.T) {
+	// OR Vx, Vy
+	rombytes := []byte{0x60, 0xFF, 0x61, 0x0F, 0x81, 0x01}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("OR instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0xFF, 0xFF, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy2(t *testing.T) {
+	// AND Vx, Vy
+	rombytes := []byte{0x60, 0xFF, 0x61, 0x0F, 0x80, 0x12}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("AND instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0x0F, 0x0F, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy3(t *testing.T) {
+	// XOR Vx, Vy
+	rombytes := []byte{0x60, 0xFF, 0x61, 0x0F, 0x80, 0x13}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("XOR instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0xF0, 0x0F, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy4_nocarry(t *testing.T) {
+	// ADD Vx, Vy
+	rombytes := []byte{0x60, 0x02, 0x61, 0x08, 0x80, 0x14}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("ADD instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0x0A, 0x08, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy4_carry(t *testing.T) {
+	// ADD Vx, Vy
+	rombytes := []byte{0x60, 0xFF, 0x61, 0x08, 0x80, 0x14}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("ADD instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0x07, 0x08, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy5_noborrow(t *testing.T) {
+	// SUB Vx, Vy
+	rombytes := []byte{0x60, 0x0A, 0x61, 0x08, 0x80, 0x15}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SUB instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0x02, 0x08, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy5_borrow(t *testing.T) {
+	// SUB Vx, Vy
+	rombytes := []byte{0x60, 0x0A, 0x61, 0x0F, 0x80, 0x15}
+	// TODO: Output 5 or 251 depends on overflow handling
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SUB instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0xFB, 0x0F, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy6_0(t *testing.T) {
+	// SUB Vx, Vy
+	rombytes := []byte{0x60, 0xF0, 0x61, 0x08, 0x80, 0x16}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SHR instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0x78, 0x08, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy6_1(t *testing.T) {
+	// SUB Vx, Vy
+	rombytes := []byte{0x60, 0x0F, 0x61, 0x08, 0x80, 0x16}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SHR instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0x07, 0x08, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy7_noborrow(t *testing.T) {
+	// SUBN Vx, Vy
+	rombytes := []byte{0x60, 0x08, 0x61, 0x0F, 0x80, 0x17}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SUBN instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0x07, 0x0F, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy7_borrow(t *testing.T) {
+	// SUBN Vx, Vy
+	rombytes := []byte{0x60, 0x0C, 0x61, 0x0A, 0x80, 0x17}
+	// TODO: Output 2 or 254 depends on overflow handling
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SUBN instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0xFE, 0x0A, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xyE_0(t *testing.T) {
+	// SHL Vx, Vy
+	rombytes := []byte{0x60, 0x0F, 0x61, 0x08, 0x80, 0x1E}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SHL instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0x1E, 0x08, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xyE_1(t *testing.T) {
+	// SHL Vx, Vy
+	rombytes := []byte{0x60, 0xFF, 0x61, 0x08, 0x80, 0x1E}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SHL instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0xFE, 0x08, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test9xy0(t *testing.T) {
+	// inverse of 5xy0
+	// SNE Vx Vy
+	rombytes := []byte{0x66, 0x04, 0x67, 0x04, 0x96, 0x70}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SNE instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x04, 0x04, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.pc != uint16(0x206) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x206))
+	}
+}
+
+func Test9xy0_noskip(t *testing.T) {
+	rombytes := []byte{0x66, 0x04, 0x67, 0x05, 0x96, 0x70}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("SNE instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0x04, 0x05, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.pc != uint16(0x206+2) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x206+2))
+	}
+}
+
+func TestAnnn(t *testing.T) {
+	rombytes := []byte{0xA0, 0x05}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(5) {
+		t.Errorf("Load instruction incorrect, got: %d, want: %d.", vm.i, uint16(5))
+	}
+	if vm.pc != uint16(0x200+2) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x200+2))
+	}
+}
+
+func TestBnnn(t *testing.T) {
+	// JP V0, addr
+	rombytes := padROM([]byte{0x60, 0x04, 0xB2, 0x66}, 0x26A)
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0) {
+		t.Errorf("Load instruction incorrect, got: %d, want: %d.", vm.i, uint16(0))
+	}
+	if vm.pc != uint16(0x26A) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x26A))
+	}
+}
+
+func TestDxyn(t *testing.T) {
+	rombytes := []byte{0x60, 0x00, 0xA0, 0x50, 0xD0, 0x05, 0x00, 0x00}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0x050) {
+		t.Errorf("DRAW instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x050))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.screen[0][0] != [16]uint8{0xF0, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][1] != [16]uint8{0x90, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][2] != [16]uint8{0x90, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][3] != [16]uint8{0x90, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][4] != [16]uint8{0xF0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected screen incorrect, got: %s\nfont memory: %s",
+			fmt.Sprint(vm.screen[0]),
+			fmt.Sprint(vm.memory[0x050:0x200]))
+	}
+}
+
+func TestDxyn_allchars(t *testing.T) {
+	rombytes := []byte{0x60, 0x00, 0x61, 0x04, 0xA0, 0x50,
+		0xD0, 0x05,
+		0x63, 0x01, 0xF3, 0x29, 0xD1, 0x05,
+		0x73, 0x01, 0x61, 0x09, 0xF3, 0x29, 0xD1, 0x05,
+		0x73, 0x01, 0x61, 0x0E, 0xF3, 0x29, 0xD1, 0x05,
+		0x73, 0x01, 0x61, 0x13, 0xF3, 0x29, 0xD1, 0x05,
+		0x73, 0x01, 0x61, 0x18, 0xF3, 0x29, 0xD1, 0x05,
+		0x73, 0x01, 0x61, 0x1D, 0xF3, 0x29, 0xD1, 0x05,
+		0x73, 0x01, 0x61, 0x22, 0xF3, 0x29, 0xD1, 0x05,
+		0x73, 0x01, 0x61, 0x27, 0xF3, 0x29, 0xD1, 0x05,
+		0x73, 0x01, 0x61, 0x2C, 0xF3, 0x29, 0xD1, 0x05,
+		0x62, 0x06,
+		0x73, 0x01, 0x61, 0x00, 0xF3, 0x29, 0xD1, 0x25,
+		0x73, 0x01, 0x61, 0x05, 0xF3, 0x29, 0xD1, 0x25,
+		0x73, 0x01, 0x61, 0x0A, 0xF3, 0x29, 0xD1, 0x25,
+		0x73, 0x01, 0x61, 0x0F, 0xF3, 0x29, 0xD1, 0x25,
+		0x73, 0x01, 0x61, 0x14, 0xF3, 0x29, 0xD1, 0x25,
+		0x73, 0x01, 0x61, 0x19, 0xF3, 0x29, 0xD1, 0x25}
+
+	vm := returnVM(rombytes)
+
+	if vm.v != [16]uint8{0, 0x19, 0x06, 0x0F, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.screen[0][0] != [16]uint8{242, 123, 210, 247, 189, 239, 0, 0} ||
+		vm.screen[0][1] != [16]uint8{150, 8, 82, 132, 5, 41, 0, 0} ||
+		vm.screen[0][2] != [16]uint8{146, 123, 222, 247, 137, 239, 0, 0} ||
+		vm.screen[0][3] != [16]uint8{146, 64, 66, 20, 145, 33, 0, 0} ||
+		vm.screen[0][4] != [16]uint8{247, 123, 194, 247, 145, 239, 0, 0} ||
+		vm.screen[0][5] != [16]uint8{0, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][6] != [16]uint8{247, 61, 207, 120, 0, 0, 0, 0} ||
+		vm.screen[0][7] != [16]uint8{148, 161, 40, 64, 0, 0, 0, 0} ||
+		vm.screen[0][8] != [16]uint8{247, 33, 47, 120, 0, 0, 0, 0} ||
+		vm.screen[0][9] != [16]uint8{148, 161, 40, 64, 0, 0, 0, 0} ||
+		vm.screen[0][10] != [16]uint8{151, 61, 207, 64, 0, 0, 0, 0} {
+		t.Errorf("Expected screen incorrect, got: %s\nfont memory: %s",
+			fmt.Sprint(vm.screen[0]),
+			fmt.Sprint(vm.memory[0x050:0x200]))
+	}
+}
+
+func TestDxyn_overlap(t *testing.T) {
+	rombytes := []byte{0x60, 0x00, 0x61, 0x04, 0x63, 0x00,
+		0x61, 0x00, 0xF3, 0x29, 0xD1, 0x05, 0x73, 0x01,
+		0x61, 0x00, 0xF3, 0x29, 0xD1, 0x05}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0x055) {
+		t.Errorf("DRAW instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x050))
+	}
+	if vm.v != [16]uint8{0, 0, 0, 0x01, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.screen[0][0] != [16]uint8{208, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][1] != [16]uint8{240, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][2] != [16]uint8{176, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][3] != [16]uint8{176, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][4] != [16]uint8{128, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected screen incorrect, got: %s\nfont memory: %s",
+			fmt.Sprint(vm.screen[0]),
+			fmt.Sprint(vm.memory[0x050:0x200]))
+	}
+}
+
+func TestDxyn_overlap2(t *testing.T) {
+	rombytes := []byte{0x60, 0x00, 0x63, 0x0F,
+		0x61, 0x00, 0xF3, 0x29, 0xD1, 0x05, 0x63, 0x00,
+		0x61, 0x02, 0xF3, 0x29, 0xD1, 0x05}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0x050) {
+		t.Errorf("DRAW instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x050))
+	}
+	if vm.v != [16]uint8{0, 0x02, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.screen[0][0] != [16]uint8{204, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][1] != [16]uint8{164, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][2] != [16]uint8{212, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][3] != [16]uint8{164, 0, 0, 0, 0, 0, 0, 0} ||
+		vm.screen[0][4] != [16]uint8{188, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected screen incorrect, got: %s\nfont memory: %s",
+			fmt.Sprint(vm.screen[0]),
+			fmt.Sprint(vm.memory[0x050:0x200]))
+	}
+}
+
+func TestFx1E(t *testing.T) {
+	// I = I + Vx
+	rombytes := []byte{0x60, 0x02, 0xA0, 0x08, 0xF0, 0x1E}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0x0A) {
+		t.Errorf("ADD instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x0A))
+	}
+	if vm.v != [16]uint8{0x02, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func TestFx29(t *testing.T) {
+	// Set I to sprite location
+	rombytes := []byte{0x60, 0x03, 0xA0, 0x08, 0xF0, 0x29}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0x05F) {
+		t.Errorf("SPRITE instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x05F))
+	}
+	if vm.v != [16]uint8{0x03, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func TestFx33(t *testing.T) {
+	// BCD of Vx in I, I+1, I+2
+	rombytes := []byte{0x60, 0xFF, 0xA5, 0x10, 0xF0, 0x33}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0x510) {
+		t.Errorf("LOAD instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x0510))
+	}
+	if vm.v != [16]uint8{0xFF, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+
+	if vm.memory[0x0510] != 2 || vm.memory[0x0511] != 5 || vm.memory[0x0512] != 5 {
+
+		t.Errorf("Expected memory incorrect, got: %s", fmt.Sprint(vm.memory[0x0510:0x0513]))
+	}
+}
+
+func TestFx55(t *testing.T) {
+	// Store registers V0 through Vx in memory starting at location I.
+	rombytes := []byte{0x60, 0xDE, 0x61, 0xAD, 0x62, 0xBE, 0x63, 0xEF, 0xA5, 0x10, 0xF3, 0x55}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0x510) {
+		t.Errorf("LOAD instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x0510))
+	}
+	if vm.v != [16]uint8{0xDE, 0xAD, 0xBE, 0xEF, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+
+	if vm.memory[0x0510] != 0xDE || vm.memory[0x0511] != 0xAD || vm.memory[0x0512] != 0xBE || vm.memory[0x0513] != 0xEF {
+
+		t.Errorf("Expected memory incorrect, got: %s", fmt.Sprint(vm.memory[0x0510:0x0514]))
+	}
+}
+
+func TestFx65(t *testing.T) {
+	// Store registers V0 through Vx in memory starting at location I.
+	rombytes := []byte{0xF0, 0x29, 0xF4, 0x65}
+	vm := returnVM(rombytes)
+
+	if vm.i != uint16(0x050) {
+		t.Errorf("LOAD instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x050))
+	}
+	if vm.v != [16]uint8{0xF0, 0x90, 0x90, 0x90, 0xF0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+
+}
+
+func Test00FF_Dxy0(t *testing.T) {
+	// HIGH, then draw a 16x16 sprite
+	rombytes := []byte{0x00, 0xFF, 0xA2, 0x0A, 0xD0, 0x00, 0x00, 0xFD, 0x00, 0x00}
+	for i := 0; i < 16; i++ {
+		rombytes = append(rombytes, 0xFF, 0x01)
+	}
+	vm := returnSCHIPVM(rombytes)
+
+	if !vm.hires || vm.width != 128 || vm.height != 64 {
+		t.Errorf("HIGH instruction incorrect, got: %dx%d", vm.width, vm.height)
+	}
+	if vm.pc != uint16(0x206) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x206))
+	}
+	if vm.screen[0][0] != [16]uint8{0xFF, 0x01} ||
+		vm.screen[0][15] != [16]uint8{0xFF, 0x01} ||
+		vm.screen[0][16] != [16]uint8{0, 0} {
+		t.Errorf("Expected screen incorrect, got: %s", fmt.Sprint(vm.screen[0]))
+	}
+}
+
+func Test00FE(t *testing.T) {
+	// HIGH then LOW
+	rombytes := []byte{0x00, 0xFF, 0x00, 0xFE}
+	vm := returnSCHIPVM(rombytes)
+
+	if vm.hires || vm.width != 64 || vm.height != 32 {
+		t.Errorf("LOW instruction incorrect, got: %dx%d", vm.width, vm.height)
+	}
+}
+
+func Test00Cn(t *testing.T) {
+	// Draw 0 then scroll down 2 lines
+	rombytes := []byte{0xA0, 0x50, 0xD0, 0x05, 0x00, 0xC2}
+	vm := returnSCHIPVM(rombytes)
+
+	if vm.screen[0][0] != [16]uint8{0, 0} ||
+		vm.screen[0][1] != [16]uint8{0, 0} ||
+		vm.screen[0][2] != [16]uint8{0xF0, 0} ||
+		vm.screen[0][6] != [16]uint8{0xF0, 0} ||
+		vm.screen[0][7] != [16]uint8{0, 0} {
+		t.Errorf("Expected screen incorrect, got: %s", fmt.Sprint(vm.screen[0]))
+	}
+}
+
+func Test00FB(t *testing.T) {
+	// Draw 0 at x=4 then scroll right 4 pixels
+	rombytes := []byte{0x60, 0x04, 0xA0, 0x50, 0xD0, 0x15, 0x00, 0xFB}
+	vm := returnSCHIPVM(rombytes)
+
+	if vm.screen[0][0] != [16]uint8{0x00, 0xF0} ||
+		vm.screen[0][1] != [16]uint8{0x00, 0x90} {
+		t.Errorf("Expected screen incorrect, got: %s", fmt.Sprint(vm.screen[0]))
+	}
+}
+
+func Test00FC(t *testing.T) {
+	// Draw 0 at x=4 then scroll left 4 pixels
+	rombytes := []byte{0x60, 0x04, 0xA0, 0x50, 0xD0, 0x15, 0x00, 0xFC}
+	vm := returnSCHIPVM(rombytes)
+
+	if vm.screen[0][0] != [16]uint8{0xF0, 0x00} ||
+		vm.screen[0][1] != [16]uint8{0x90, 0x00} {
+		t.Errorf("Expected screen incorrect, got: %s", fmt.Sprint(vm.screen[0]))
+	}
+}
+
+func TestFx30(t *testing.T) {
+	// Set I to big sprite location
+	rombytes := []byte{0x60, 0x03, 0xF0, 0x30}
+	vm := returnSCHIPVM(rombytes)
+
+	if vm.i != uint16(0x0BE) {
+		t.Errorf("SPRITE instruction incorrect, got: %d, want: %d.", vm.i, uint16(0x0BE))
+	}
+	if vm.memory[vm.i] != 0x3C {
+		t.Errorf("Expected font memory incorrect, got: %s", fmt.Sprint(vm.memory[vm.i:vm.i+10]))
+	}
+}
+
+func TestFx75_Fx85(t *testing.T) {
+	// Save V0-V2 to RPL flags, clear them and read them back
+	rombytes := []byte{0x60, 0xDE, 0x61, 0xAD, 0x62, 0xBE, 0xF2, 0x75,
+		0x60, 0x00, 0x61, 0x00, 0x62, 0x00, 0xF1, 0x85}
+	vm := returnSCHIPVM(rombytes)
+
+	if vm.rpl != [16]uint8{0xDE, 0xAD, 0xBE} {
+		t.Errorf("Expected RPL flags incorrect, got: %s", fmt.Sprint(vm.rpl))
+	}
+	if vm.v != [16]uint8{0xDE, 0xAD, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func returnPlatformVM(rombytes []byte, platform string) *Machine {
+	return runVM(rombytes, Config{Platform: platform, Quirks: PlatformQuirks(platform)})
+}
+
+func TestF000(t *testing.T) {
+	// Long load of I, skipped as a whole by 3xkk
+	rombytes := []byte{0xF0, 0x00, 0xBE, 0xEF, 0x30, 0x00, 0xF0, 0x00, 0x12, 0x34, 0x60, 0x01}
+	vm := returnPlatformVM(rombytes, "xo-chip")
+
+	if vm.i != uint16(0xBEEF) {
+		t.Errorf("LD I instruction incorrect, got: %x, want: %x.", vm.i, uint16(0xBEEF))
+	}
+	if vm.v[0] != 1 {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+	if vm.pc != uint16(0x20C) {
+		t.Errorf("Expected program counter incorrect, got: %d, want: %d.", vm.pc, uint16(0x20C))
+	}
+}
+
+func Test5xy2_5xy3(t *testing.T) {
+	// Save V1-V3 in reverse order, then load them into V4-V6
+	rombytes := []byte{0x61, 0x01, 0x62, 0x02, 0x63, 0x03, 0xA5, 0x00, 0x53, 0x12, 0x54, 0x63}
+	vm := returnPlatformVM(rombytes, "xo-chip")
+
+	if vm.i != uint16(0x500) {
+		t.Errorf("SAVE instruction incorrect, got: %x, want: %x.", vm.i, uint16(0x500))
+	}
+	if vm.memory[0x500] != 3 || vm.memory[0x501] != 2 || vm.memory[0x502] != 1 {
+		t.Errorf("Expected memory incorrect, got: %s", fmt.Sprint(vm.memory[0x500:0x503]))
+	}
+	if vm.v != [16]uint8{0, 1, 2, 3, 3, 2, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func TestFn01(t *testing.T) {
+	// Draw font 0 on both planes, the second plane's data follows the first
+	rombytes := []byte{0xF3, 0x01, 0xA0, 0x50, 0xD0, 0x05}
+	vm := returnPlatformVM(rombytes, "xo-chip")
+
+	if vm.planes != 3 {
+		t.Errorf("PLANE instruction incorrect, got: %d, want: %d.", vm.planes, 3)
+	}
+	if vm.screen[0][0] != [16]uint8{0xF0} || vm.screen[0][4] != [16]uint8{0xF0} ||
+		vm.screen[1][0] != [16]uint8{0x20} || vm.screen[1][4] != [16]uint8{0x70} {
+		t.Errorf("Expected screen incorrect, got: %s", fmt.Sprint(vm.screen))
+	}
+}
+
+func TestF002_Fx3A(t *testing.T) {
+	// Load audio pattern from the font and set the pitch
+	rombytes := []byte{0xA0, 0x50, 0xF0, 0x02, 0x60, 0x70, 0xF0, 0x3A}
+	vm := returnPlatformVM(rombytes, "xo-chip")
+
+	if vm.pattern[0] != 0xF0 || vm.pattern[15] != 0xF0 {
+		t.Errorf("Expected audio pattern incorrect, got: %s", fmt.Sprint(vm.pattern))
+	}
+	if vm.pitch != 0x70 || vm.playbackRate() != 8000 {
+		t.Errorf("Expected pitch incorrect, got: %d, rate: %f", vm.pitch, vm.playbackRate())
+	}
+}
+
+func TestVIPHires(t *testing.T) {
+	// Jump to 0x260 at 0x200 enables 64x64 mode and starts at 0x2C0
+	rombytes := make([]byte, 0xC4)
+	copy(rombytes, []byte{0x12, 0x60})
+	copy(rombytes[0xC0:], []byte{0x60, 0x05, 0x02, 0x30})
+	vm := returnVM(rombytes)
+
+	if vm.width != 64 || vm.height != 64 {
+		t.Errorf("Expected resolution incorrect, got: %dx%d, want: 64x64", vm.width, vm.height)
+	}
+	if vm.v[0] != 5 {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func TestVIPHires_0230(t *testing.T) {
+	// Draw 0 at the bottom of the 64x64 screen then clear it with 0230
+	rombytes := make([]byte, 0xC8)
+	copy(rombytes, []byte{0x12, 0x60})
+	copy(rombytes[0xC0:], []byte{0x60, 0x3A, 0xA0, 0x50, 0xD1, 0x05})
+	vm := returnVM(rombytes)
+
+	if vm.screen[0][0x3A] != [16]uint8{0xF0} || vm.screen[0][0x3E] != [16]uint8{0xF0} {
+		t.Errorf("Expected screen incorrect, got: %s", fmt.Sprint(vm.screen[0]))
+	}
+
+	rombytes = append(rombytes[:0xC6], 0x02, 0x30)
+	vm = returnVM(rombytes)
+
+	if vm.screen[0] != [64][16]uint8{} {
+		t.Errorf("Expected screen incorrect, got: %s", fmt.Sprint(vm.screen[0]))
+	}
+}
+
+func returnQuirksVM(rombytes []byte, quirks Quirks) *Machine {
+	return runVM(rombytes, Config{Quirks: quirks})
+}
+
+func Test8xy6_vip(t *testing.T) {
+	// SHR Vx, Vy shifts Vy into Vx
+	rombytes := []byte{0x60, 0xF0, 0x61, 0x09, 0x80, 0x16}
+	vm := returnQuirksVM(rombytes, QuirksPresets["vip"])
+
+	if vm.v != [16]uint8{0x04, 0x09, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xyE_vip(t *testing.T) {
+	// SHL Vx, Vy shifts Vy into Vx
+	rombytes := []byte{0x60, 0x01, 0x61, 0x81, 0x80, 0x1E}
+	vm := returnQuirksVM(rombytes, QuirksPresets["vip"])
+
+	if vm.v != [16]uint8{0x02, 0x81, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func Test8xy1_vfreset(t *testing.T) {
+	// OR Vx, Vy resets VF
+	rombytes := []byte{0x6F, 0x05, 0x60, 0xF0, 0x61, 0x0F, 0x80, 0x11}
+	vm := returnQuirksVM(rombytes, QuirksPresets["vip"])
+
+	if vm.v != [16]uint8{0xFF, 0x0F, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func TestFx55_vip(t *testing.T) {
+	// Store V0-V3 and increment I
+	rombytes := []byte{0x60, 0xDE, 0x61, 0xAD, 0x62, 0xBE, 0x63, 0xEF, 0xA5, 0x10, 0xF3, 0x55}
+	vm := returnQuirksVM(rombytes, QuirksPresets["vip"])
+
+	if vm.i != uint16(0x514) {
+		t.Errorf("LOAD instruction incorrect, got: %x, want: %x.", vm.i, uint16(0x514))
+	}
+	if vm.memory[0x0510] != 0xDE || vm.memory[0x0513] != 0xEF {
+		t.Errorf("Expected memory incorrect, got: %s", fmt.Sprint(vm.memory[0x0510:0x0514]))
+	}
+}
+
+func TestBxnn_jump(t *testing.T) {
+	// JP V2, addr
+	rombytes := padROM([]byte{0x60, 0x04, 0x62, 0x08, 0xB2, 0x66}, 0x26E)
+	vm := returnQuirksVM(rombytes, QuirksPresets["schip-modern"])
+
+	if vm.pc != uint16(0x26E) {
+		t.Errorf("Expected program counter incorrect, got: %x, want: %x.", vm.pc, uint16(0x26E))
+	}
+}
+
+func TestDxyn_clip(t *testing.T) {
+	// Draw 0 at (62, 30), overflowing pixels are clipped
+	rombytes := []byte{0x60, 0x3E, 0x61, 0x1E, 0xA0, 0x50, 0xD0, 0x15}
+	vm := returnQuirksVM(rombytes, Quirks{Clip: true})
+
+	if vm.screen[0][30] != [16]uint8{0, 0, 0, 0, 0, 0, 0, 0x03} ||
+		vm.screen[0][31] != [16]uint8{0, 0, 0, 0, 0, 0, 0, 0x02} ||
+		vm.screen[0][0] != [16]uint8{} || vm.screen[0][1] != [16]uint8{} {
+		t.Errorf("Expected screen incorrect, got: %s", fmt.Sprint(vm.screen[0]))
+	}
+}
+
+func TestDxyn_displaywait(t *testing.T) {
+	// Instructions after a draw wait for the next timer tick
+	rombytes := []byte{0xA0, 0x50, 0xD0, 0x05, 0x70, 0x01}
+	vm := returnQuirksVM(rombytes, Quirks{DisplayWait: true})
+
+	if vm.v[0] != 1 || vm.vblankWait {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func returnFaultVM(rombytes []byte, policy map[FaultKind]FaultPolicy) *Machine {
+	return runVM(rombytes, Config{FaultPolicy: policy})
+}
+
+func TestFault_underflow(t *testing.T) {
+	// RET with an empty stack halts
+	rombytes := []byte{0x60, 0x01, 0x00, 0xEE, 0x60, 0x02}
+	vm := returnFaultVM(rombytes, nil)
+
+	if !errors.Is(vm.Fault(), FaultStackUnderflow) {
+		t.Errorf("Expected fault incorrect, got: %v", vm.Fault())
+	}
+	if vm.pc != uint16(0x202) || vm.v[0] != 1 {
+		t.Errorf("Expected program counter incorrect, got: %x, want: %x.", vm.pc, uint16(0x202))
+	}
+	if running, err := vm.Step(); running || err != vm.Fault() {
+		t.Errorf("Halted machine continued, got: %t, %v", running, err)
+	}
+}
+
+func TestFault_illegalJump(t *testing.T) {
+	// JP into the interpreter area halts
+	rombytes := []byte{0x11, 0x00}
+	vm := returnFaultVM(rombytes, nil)
+
+	fault, ok := vm.Fault().(*Fault)
+	if !ok || fault.Kind != FaultIllegalJump || fault.Addr != 0x100 || fault.PC != 0x200 || fault.Opcode != 0x1100 {
+		t.Errorf("Expected fault incorrect, got: %v", vm.Fault())
+	}
+}
+
+func TestFault_unknownOpcode_ignore(t *testing.T) {
+	// Unknown opcodes are skipped
+	rombytes := []byte{0x80, 0x1F, 0x60, 0x05, 0xE0, 0x00, 0x61, 0x06}
+	vm := returnFaultVM(rombytes, map[FaultKind]FaultPolicy{FaultUnknownOpcode: PolicyIgnore})
+
+	if vm.Fault() != nil {
+		t.Errorf("Unexpected fault: %v", vm.Fault())
+	}
+	if vm.v[0] != 5 || vm.v[1] != 6 {
+		t.Errorf("Expected V incorrect, got: %s", fmt.Sprint(vm.v))
+	}
+}
+
+func TestFault_longLoad_ignore(t *testing.T) {
+	// Ignored faults skip the whole of a 4-byte F000 nnnn, not just its first half
+	vm := New(Config{Platform: PlatformXOChip, FaultPolicy: map[FaultKind]FaultPolicy{FaultMemoryAccess: PolicyIgnore}})
+	vm.WriteMemory(0xFFFE, 0xF0)
+	vm.WriteMemory(0xFFFF, 0x00)
+	vm.SetPC(0xFFFE)
+
+	if running, err := vm.Step(); !running || err != nil || vm.pc != 0x0002 {
+		t.Errorf("Expected program counter incorrect, got: %t, %v, PC: %x", running, err, vm.pc)
+	}
+}
+
+func TestFault_break(t *testing.T) {
+	// Breaking faults leave the machine at the faulting instruction
+	vm := New(Config{FaultPolicy: map[FaultKind]FaultPolicy{FaultUnknownOpcode: PolicyBreak}})
+	vm.LoadROM([]byte{0x80, 0x1F, 0x60, 0x05, 0x61, 0x06})
+
+	running, err := vm.Step()
+	if !running || !errors.Is(err, FaultUnknownOpcode) || vm.pc != 0x200 {
+		t.Errorf("Expected break incorrect, got: %t, %v, PC: %x", running, err, vm.pc)
+	}
+	vm.SetPC(0x202)
+	if running, err = vm.Step(); !running || err != nil || vm.v[0] != 5 {
+		t.Errorf("Expected resume incorrect, got: %t, %v, V0: %d", running, err, vm.v[0])
+	}
+}
+
+func TestParseFaultPolicies(t *testing.T) {
+	policies, err := ParseFaultPolicies("stack-underflow=halt,log,unknown-opcode=ignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policies[FaultStackUnderflow] != PolicyHalt || policies[FaultUnknownOpcode] != PolicyIgnore ||
+		policies[FaultIllegalJump] != PolicyLog {
+		t.Errorf("Expected policies incorrect, got: %v", policies)
+	}
+	if _, err := ParseFaultPolicies("unknown-opcode=explode"); err == nil {
+		t.Errorf("ParseFaultPolicies accepted an unknown policy")
+	}
+}
+
+func TestFault_stackOverflow(t *testing.T) {
+	// CALL itself until the stack is full
+	rombytes := []byte{0x70, 0x01, 0x22, 0x00}
+	vm := returnFaultVM(rombytes, nil)
+
+	if !errors.Is(vm.Fault(), FaultStackOverflow) || vm.sp != 16 || vm.v[0] != 17 {
+		t.Errorf("Expected fault incorrect, got: %v", vm.Fault())
+	}
+
+	vm = runVM(rombytes, Config{StackDepth: StackDepthVIP})
+	if !errors.Is(vm.Fault(), FaultStackOverflow) || vm.sp != 12 || len(vm.Stack()) != 12 {
+		t.Errorf("Expected fault incorrect, got: %v", vm.Fault())
+	}
+}
+
+func TestStackUnlimited(t *testing.T) {
+	// CALL 100 levels deep, then return through all of them
+	rombytes := []byte{0x22, 0x04, 0x12, 0x0C, 0x70, 0x01, 0x30, 0x64, 0x22, 0x04, 0x00, 0xEE}
+	vm := runVM(rombytes, Config{StackDepth: StackUnlimited})
+
+	if vm.Fault() != nil || vm.sp != 0 || vm.v[0] != 100 {
+		t.Errorf("Expected stack incorrect, got: %v, SP: %d, V0: %d", vm.Fault(), vm.sp, vm.v[0])
+	}
+}
+
+func TestFault_memoryAccess(t *testing.T) {
+	// Fx55 past the end of 4 KiB memory
+	rombytes := []byte{0xAF, 0xFE, 0xF2, 0x55}
+	vm := returnFaultVM(rombytes, nil)
+
+	fault, ok := vm.Fault().(*Fault)
+	if !ok || fault.Kind != FaultMemoryAccess || fault.Addr != 0xFFE || fault.Size != 3 || fault.I != 0xFFE {
+		t.Errorf("Expected fault incorrect, got: %v", vm.Fault())
+	}
+	if vm.memory[0xFFE] != 0 || vm.i != 0xFFE {
+		t.Errorf("Faulting instruction changed state, I: %x", vm.i)
+	}
+
+	// XO-CHIP has 64 KiB
+	vm = runVM(rombytes, Config{Platform: PlatformXOChip})
+	if vm.Fault() != nil {
+		t.Errorf("Unexpected fault: %v", vm.Fault())
+	}
+}
+
+func TestFault_memoryAccessDxyn(t *testing.T) {
+	// 16x16 sprite read past the end of memory
+	rombytes := []byte{0xAF, 0xF0, 0x00, 0xFF, 0xD0, 0x00}
+	vm := runVM(rombytes, Config{Platform: PlatformSCHIP})
+
+	if !errors.Is(vm.Fault(), FaultMemoryAccess) || vm.Fault().(*Fault).Size != 32 {
+		t.Errorf("Expected fault incorrect, got: %v", vm.Fault())
+	}
+}
+
+func TestFault_schipOnChip8(t *testing.T) {
+	// Super CHIP-8 instructions are unknown on the chip-8 platform
+	for _, opcode := range []uint16{0x00C2, 0x00FB, 0x00FC, 0x00FD, 0x00FE, 0x00FF, 0xF030, 0xF075, 0xF085} {
+		vm := runVM([]byte{byte(opcode >> 8), byte(opcode)}, Config{Platform: PlatformChip8})
+		if !errors.Is(vm.Fault(), FaultUnknownOpcode) || vm.pc != 0x200 {
+			t.Errorf("Expected fault for %04X incorrect, got: %v", opcode, vm.Fault())
+		}
+		vm = runVM([]byte{byte(opcode >> 8), byte(opcode)}, Config{Platform: PlatformSCHIP})
+		if vm.Fault() != nil {
+			t.Errorf("Unexpected fault for %04X on schip: %v", opcode, vm.Fault())
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package chip8
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MovieVersion : Version of the movie format written by Movie.Write
+const MovieVersion = 1
+
+// ErrMovieROM : The movie was recorded with a different ROM
+var ErrMovieROM = errors.New("movie is for a different ROM")
+
+// Movie : Recording of the keys held each frame and the keys given to Fx0A, along with the ROM and
+// random number seed, so a run from Reset can be replayed exactly
+type Movie struct {
+	ROMHash string      // SHA-1 hash of the ROM, as from ROMHash
+	Seed    int64       // Config.Seed of the recorded run
+	Keys    []uint16    // keys held each frame, bit n for key n
+	Waits   []MovieWait // keys returned by WaitForKeyPress, in order
+}
+
+// MovieWait : Key given to Fx0A during a frame
+type MovieWait struct {
+	Frame uint64
+	Key   uint8
+}
+
+// NewMovie : Empty movie of the ROM loaded in vm, to replay with its seed
+func NewMovie(vm *Machine) *Movie {
+	return &Movie{ROMHash: ROMHash(vm.rom), Seed: vm.Seed()}
+}
+
+// Check : Whether the movie was recorded with rom, returns ErrMovieROM if not
+func (movie *Movie) Check(rom []byte) error {
+	if movie.ROMHash != ROMHash(rom) {
+		return ErrMovieROM
+	}
+	return nil
+}
+
+// Write : Write the movie as text: a header, then the frames where the keys change and the Fx0A keys
+//
+//	chip8go movie 1
+//	rom 5e1f...
+//	seed 1712345678
+//	frames 600
+//	keys 60 0020
+//	wait 75 5
+func (movie *Movie) Write(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "chip8go movie %d\nrom %s\nseed %d\nframes %d\n", MovieVersion, movie.ROMHash, movie.Seed, len(movie.Keys))
+	var held uint16
+	waits := movie.Waits
+	for frame, keys := range movie.Keys {
+		if keys != held {
+			fmt.Fprintf(buf, "keys %d %04x\n", frame, keys)
+			held = keys
+		}
+		for len(waits) > 0 && waits[0].Frame <= uint64(frame) {
+			fmt.Fprintf(buf, "wait %d %x\n", waits[0].Frame, waits[0].Key)
+			waits = waits[1:]
+		}
+	}
+	for _, wait := range waits {
+		fmt.Fprintf(buf, "wait %d %x\n", wait.Frame, wait.Key)
+	}
+	return buf.Flush()
+}
+
+// ReadMovie : Read a movie written by Movie.Write
+func ReadMovie(r io.Reader) (*Movie, error) {
+	movie := &Movie{}
+	scanner := bufio.NewScanner(r)
+	var version, frames int
+	header := []struct {
+		format string
+		value  interface{}
+	}{
+		{"chip8go movie %d", &version},
+		{"rom %s", &movie.ROMHash},
+		{"seed %d", &movie.Seed},
+		{"frames %d", &frames},
+	}
+	for _, field := range header {
+		if !scanner.Scan() {
+			return nil, errors.New("truncated movie header")
+		}
+		if _, err := fmt.Sscanf(scanner.Text(), field.format, field.value); err != nil {
+			return nil, fmt.Errorf("bad movie header: %s", scanner.Text())
+		}
+	}
+	if version != MovieVersion {
+		return nil, fmt.Errorf("unsupported movie version: %d", version)
+	}
+	if frames < 0 {
+		return nil, fmt.Errorf("bad movie length: %d", frames)
+	}
+
+	movie.Keys = make([]uint16, frames)
+	var held uint16
+	next := 0 // first frame without keys yet
+	for line := 5; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		var frame int
+		switch {
+		case text == "":
+		case strings.HasPrefix(text, "keys "):
+			var keys uint16
+			if _, err := fmt.Sscanf(text, "keys %d %x", &frame, &keys); err != nil || frame < next || frame >= frames {
+				return nil, fmt.Errorf("bad movie keys on line %d: %s", line, text)
+			}
+			for ; next < frame; next++ {
+				movie.Keys[next] = held
+			}
+			held = keys
+		case strings.HasPrefix(text, "wait "):
+			var key uint8
+			if _, err := fmt.Sscanf(text, "wait %d %x", &frame, &key); err != nil || frame < 0 || key > 0xF {
+				return nil, fmt.Errorf("bad movie wait on line %d: %s", line, text)
+			}
+			movie.Waits = append(movie.Waits, MovieWait{Frame: uint64(frame), Key: key})
+		default:
+			return nil, fmt.Errorf("bad movie line %d: %s", line, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for ; next < frames; next++ {
+		movie.Keys[next] = held
+	}
+	return movie, nil
+}
+
+// heldKeys : Keys held on keyboard at the start of frame, bit n for key n
+func heldKeys(keyboard Keyboard, frame uint64) uint16 {
+	if keyboard, ok := keyboard.(FrameKeyboard); ok {
+		keyboard.SampleKeys(frame)
+	}
+	var keys uint16
+	for key := uint8(0); key < 16; key++ {
+		if keyboard.IsKeyPressed(key) {
+			keys |= 1 << key
+		}
+	}
+	return keys
+}
+
+// MovieRecorder : Keyboard recording the keys of another Keyboard to a Movie, a frame at a time
+// Rewinding truncates the movie to the frame rewound to, so it follows the run that was kept.
+type MovieRecorder struct {
+	Movie    *Movie
+	keyboard Keyboard
+	keys     uint16
+	frame    uint64
+}
+
+// NewMovieRecorder : Record the keys of keyboard to movie
+func NewMovieRecorder(keyboard Keyboard, movie *Movie) *MovieRecorder {
+	return &MovieRecorder{Movie: movie, keyboard: keyboard}
+}
+
+// SampleKeys : Record the keys held at the start of frame
+func (recorder *MovieRecorder) SampleKeys(frame uint64) {
+	recorder.keys = heldKeys(recorder.keyboard, frame)
+	recorder.frame = frame
+	movie := recorder.Movie
+	if frame < uint64(len(movie.Keys)) {
+		movie.Keys = movie.Keys[:frame]
+		for len(movie.Waits) > 0 && movie.Waits[len(movie.Waits)-1].Frame >= frame {
+			movie.Waits = movie.Waits[:len(movie.Waits)-1]
+		}
+	}
+	for uint64(len(movie.Keys)) < frame {
+		movie.Keys = append(movie.Keys, recorder.keys) // frames run without sampling, e.g. from a save state
+	}
+	movie.Keys = append(movie.Keys, recorder.keys)
+}
+
+// WaitForKeyPress : Wait for a key on the recorded keyboard, recording it
+func (recorder *MovieRecorder) WaitForKeyPress() (uint8, bool) {
+	key, running := recorder.keyboard.WaitForKeyPress()
+	if running {
+		recorder.Movie.Waits = append(recorder.Movie.Waits, MovieWait{Frame: recorder.frame, Key: key})
+	}
+	return key, running
+}
+
+// PollKeyPress : Poll the recorded keyboard for Fx0A, recording the key pressed
+func (recorder *MovieRecorder) PollKeyPress() (uint8, bool, bool) {
+	key, pressed, running := pollKey(recorder.keyboard)
+	if pressed {
+		recorder.Movie.Waits = append(recorder.Movie.Waits, MovieWait{Frame: recorder.frame, Key: key})
+	}
+	return key, pressed, running
+}
+
+// IsKeyPressed : Whether the key was held at the start of the frame
+func (recorder *MovieRecorder) IsKeyPressed(key uint8) bool {
+	return recorder.keys&(1<<(key&0xF)) != 0
+}
+
+// MoviePlayer : Keyboard replaying a Movie, then handing over to another Keyboard (if not nil)
+type MoviePlayer struct {
+	movie  *Movie
+	after  Keyboard
+	keys   uint16
+	frame  uint64
+	played uint64 // frames of the movie sampled
+	wait   int    // index of the next wait
+}
+
+// NewMoviePlayer : Replay movie, then the keys of after, which may be nil for no keys
+func NewMoviePlayer(movie *Movie, after Keyboard) *MoviePlayer {
+	return &MoviePlayer{movie: movie, after: after}
+}
+
+// Done : Whether the movie has been played to the end
+func (player *MoviePlayer) Done() bool {
+	return player.played >= uint64(len(player.movie.Keys)) && player.wait >= len(player.movie.Waits)
+}
+
+// SampleKeys : Take the keys held during frame from the movie, or after it from the next keyboard
+func (player *MoviePlayer) SampleKeys(frame uint64) {
+	player.frame = frame
+	switch {
+	case frame < uint64(len(player.movie.Keys)):
+		player.keys = player.movie.Keys[frame]
+		player.played = frame + 1
+	case player.after != nil:
+		player.keys = heldKeys(player.after, frame)
+	default:
+		player.keys = 0
+	}
+}
+
+// WaitForKeyPress : Next key given to Fx0A in the movie, then waiting on the next keyboard
+func (player *MoviePlayer) WaitForKeyPress() (uint8, bool) {
+	if player.wait < len(player.movie.Waits) {
+		wait := player.movie.Waits[player.wait]
+		player.wait++
+		return wait.Key, true
+	}
+	if player.after == nil {
+		return 0, false
+	}
+	return player.after.WaitForKeyPress()
+}
+
+// PollKeyPress : Key given to Fx0A in the movie once its frame is reached, then polling the next keyboard
+func (player *MoviePlayer) PollKeyPress() (uint8, bool, bool) {
+	if player.wait < len(player.movie.Waits) {
+		wait := player.movie.Waits[player.wait]
+		if wait.Frame > player.frame {
+			return 0, false, true
+		}
+		player.wait++
+		return wait.Key, true, true
+	}
+	if player.after == nil {
+		return 0, false, false
+	}
+	return pollKey(player.after)
+}
+
+// IsKeyPressed : Whether the key was held at the start of the frame
+func (player *MoviePlayer) IsKeyPressed(key uint8) bool {
+	return player.keys&(1<<(key&0xF)) != 0
+}
@@ -0,0 +1,138 @@
+package chip8
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// movieROM waits for a key, then keeps adding random numbers, and more while key 5 is held
+var movieROM = []byte{
+	0xF5, 0x0A, // LD V5, K
+	0xC0, 0xFF, // RND V0, 0xFF
+	0x61, 0x05, // LD V1, 5
+	0xE1, 0xA1, // SKNP V1
+	0x82, 0x04, // ADD V2, V0
+	0x83, 0x04, // ADD V3, V0
+	0x12, 0x02, // JP 0x202
+}
+
+func TestSeededRandom(t *testing.T) {
+	rom := []byte{0xC0, 0xFF, 0xC1, 0xFF, 0xC2, 0x0F, 0x12, 0x06}
+	run := func(vm *Machine) [16]uint8 {
+		for i := 0; i < 3; i++ {
+			vm.Step()
+		}
+		return vm.v
+	}
+	vm := New(Config{Seed: 42})
+	vm.LoadROM(rom)
+	first := run(vm)
+	vm.Reset()
+	if again := run(vm); again != first {
+		t.Errorf("Expected Reset to repeat the random numbers, got: %v, want: %v", again, first)
+	}
+	other := New(Config{Seed: 43})
+	other.LoadROM(rom)
+	if run(other) == first {
+		t.Error("Expected different seeds to give different numbers")
+	}
+	if first[2] > 0x0F {
+		t.Errorf("Expected random number masked by kk, got: 0x%x", first[2])
+	}
+}
+
+func recordMovie(t *testing.T, frames int) (*Movie, *Machine) {
+	script, _ := ParseInputScript("3:7 10:+5 20:-5 25:5")
+	keyboard := NewScriptedKeyboard(script)
+	recorder := NewMovieRecorder(keyboard, nil)
+	vm := New(Config{Keyboard: recorder})
+	if err := vm.LoadROM(movieROM); err != nil {
+		t.Fatal(err)
+	}
+	recorder.Movie = NewMovie(vm)
+	for frame := 0; frame < frames; frame++ {
+		keyboard.SetFrame(frame)
+		if _, err := vm.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return recorder.Movie, vm
+}
+
+func TestMovieReplay(t *testing.T) {
+	movie, recorded := recordMovie(t, 40)
+	if len(movie.Keys) != 40 || len(movie.Waits) != 1 || movie.Waits[0].Key != 7 {
+		t.Fatalf("Expected movie incorrect, got: %d frames, waits: %v", len(movie.Keys), movie.Waits)
+	}
+
+	var buf bytes.Buffer
+	if err := movie.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadMovie(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, movie) {
+		t.Fatalf("Expected movie to read back as written, got: %+v, want: %+v", read, movie)
+	}
+	if err := read.Check(movieROM); err != nil {
+		t.Error(err)
+	}
+	if err := read.Check([]byte{0x12, 0x00}); err != ErrMovieROM {
+		t.Errorf("Expected ErrMovieROM, got: %v", err)
+	}
+
+	player := NewMoviePlayer(read, nil)
+	vm := New(Config{Keyboard: player, Seed: read.Seed})
+	vm.LoadROM(movieROM)
+	for frame := 0; frame < 40; frame++ {
+		if _, err := vm.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !player.Done() {
+		t.Error("Expected movie to be played to the end")
+	}
+	if vm.v != recorded.v || vm.pc != recorded.pc || vm.Cycles() != recorded.Cycles() {
+		t.Errorf("Expected replay to match, got: %v, want: %v", vm.v, recorded.v)
+	}
+}
+
+func TestMovieRecorderRewind(t *testing.T) {
+	script, _ := ParseInputScript("0:1 5:+5")
+	keyboard := NewScriptedKeyboard(script)
+	recorder := NewMovieRecorder(keyboard, nil)
+	vm := New(Config{Keyboard: recorder})
+	vm.LoadROM(movieROM)
+	recorder.Movie = NewMovie(vm)
+	rewind := NewRewind(vm, 60)
+	for frame := 0; frame < 10; frame++ {
+		keyboard.SetFrame(frame)
+		vm.RunFrame()
+		rewind.Record()
+	}
+	for i := 0; i < 4; i++ {
+		rewind.Back()
+	}
+	vm.RunFrame()
+	if uint64(len(recorder.Movie.Keys)) != vm.Frames() || vm.Frames() >= 10 {
+		t.Errorf("Expected movie truncated to the rewound run, got: %d frames at frame %d", len(recorder.Movie.Keys), vm.Frames())
+	}
+}
+
+func TestReadMovieErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"chip8go movie 2\nrom x\nseed 1\nframes 0\n",
+		"chip8go movie 1\nrom x\nseed 1\nframes 10\nkeys 20 0001\n",
+		"chip8go movie 1\nrom x\nseed 1\nframes 10\nkeys 5 0001\nkeys 2 0000\n",
+		"chip8go movie 1\nrom x\nseed 1\nframes 10\nwait 5 10\n",
+		"chip8go movie 1\nrom x\nseed 1\nframes 10\njump 5\n",
+	} {
+		if _, err := ReadMovie(bytes.NewBufferString(text)); err == nil {
+			t.Errorf("Expected error reading movie: %q", text)
+		}
+	}
+}
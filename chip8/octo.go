@@ -0,0 +1,972 @@
+package chip8
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// OctoError : Error in Octo source, at a line and column of a file
+type OctoError struct {
+	File   string
+	Line   int
+	Column int
+	Err    string
+}
+
+func (e *OctoError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Err)
+}
+
+// octoToken : Word of Octo source and where it starts
+type octoToken struct {
+	text      string
+	line, col int
+}
+
+// octoFixup : Address operand to fill in once a label is defined
+type octoFixup struct {
+	addr  int    // address of the instruction
+	kind  string // "long" for i := long, "unpack" for :unpack, otherwise a 12 bit address
+	label string
+	token octoToken
+}
+
+// octoControl : Open if/else or loop, with the jumps to fill in when it ends
+type octoControl struct {
+	kind   string // "if", "else" or "loop"
+	token  octoToken
+	jump   int   // address of the jump past the block, for if and else
+	start  int   // address of the start of a loop
+	whiles []int // addresses of the jumps out of a loop
+}
+
+type octoMacro struct {
+	params []string
+	body   []octoToken
+}
+
+type octoCompiler struct {
+	file     string
+	tokens   []octoToken
+	pos      int
+	memory   []byte
+	here     int // address the next byte is compiled to
+	end      int // end of the program
+	labels   map[string]int
+	consts   map[string]float64
+	aliases  map[string]int // register names
+	macros   map[string]*octoMacro
+	fixups   []octoFixup
+	controls []octoControl
+	symbols  *Symbols
+	started  bool // whether anything has been compiled, after any jump to main
+}
+
+// CompileOcto : Compile an Octo program into a ROM loaded at 0x200, returning the ROM and its symbols
+// file names the source in errors and the symbols' source lines.
+//
+// The statements and directives of the Octo language are supported: labels (": name") and
+// calls, ":alias", ":const", ":calc" (whose operators have no precedence and evaluate right to
+// left, as in Octo), ":macro", ":byte", ":pointer", ":org" and ":unpack", register and memory
+// statements such as "v0 += 1" and "i := long label", if/then, if/begin/else/end, and
+// loop/while/again, where the comparisons <, >, <= and >= use VF. Numbers on their own are
+// bytes, for sprites and other data.
+// Program execution starts at the label main, with a jump to it at 0x200 unless it comes first.
+func CompileOcto(file string, source io.Reader) ([]byte, *Symbols, error) {
+	c := &octoCompiler{
+		file:    file,
+		memory:  make([]byte, 0x10000),
+		here:    0x200,
+		end:     0x200,
+		labels:  make(map[string]int),
+		consts:  make(map[string]float64),
+		aliases: make(map[string]int),
+		macros:  make(map[string]*octoMacro),
+		symbols: &Symbols{},
+	}
+	if err := c.tokenize(source); err != nil {
+		return nil, nil, err
+	}
+	for c.pos < len(c.tokens) {
+		if err := c.statement(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(c.controls) > 0 {
+		open := c.controls[len(c.controls)-1]
+		return nil, nil, c.errorf(open.token, "%s without a matching end", open.kind)
+	}
+	for _, fixup := range c.fixups {
+		addr, ok := c.labels[fixup.label]
+		if !ok {
+			return nil, nil, c.errorf(fixup.token, "undefined label: %s", fixup.label)
+		}
+		if fixup.kind == "long" {
+			c.memory[fixup.addr+2], c.memory[fixup.addr+3] = byte(addr>>8), byte(addr)
+			continue
+		}
+		if addr > 0xFFF {
+			return nil, nil, c.errorf(fixup.token, "label %s at 0x%X is out of reach, use i := long", fixup.label, addr)
+		}
+		if fixup.kind == "unpack" {
+			c.memory[fixup.addr+1] |= byte(addr >> 8)
+			c.memory[fixup.addr+3] = byte(addr)
+			continue
+		}
+		c.memory[fixup.addr] |= byte(addr >> 8)
+		c.memory[fixup.addr+1] = byte(addr)
+	}
+	for name, addr := range c.labels {
+		c.symbols.Labels = append(c.symbols.Labels, Label{Name: name, Addr: uint16(addr)})
+	}
+	c.symbols.sort()
+	return append([]byte(nil), c.memory[0x200:c.end]...), c.symbols, nil
+}
+
+func (c *octoCompiler) errorf(token octoToken, format string, args ...interface{}) error {
+	return &OctoError{File: c.file, Line: token.line, Column: token.col, Err: fmt.Sprintf(format, args...)}
+}
+
+// tokenize : Split the source into words, dropping # comments
+func (c *octoCompiler) tokenize(source io.Reader) error {
+	scanner := bufio.NewScanner(source)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for i := 0; i < len(text); {
+			switch {
+			case text[i] == '#':
+				i = len(text)
+			case text[i] == ' ' || text[i] == '\t' || text[i] == '\r':
+				i++
+			default:
+				j := i
+				for j < len(text) && text[j] != ' ' && text[j] != '\t' && text[j] != '\r' && text[j] != '#' {
+					j++
+				}
+				c.tokens = append(c.tokens, octoToken{text: text[i:j], line: line, col: i + 1})
+				i = j
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// next : Take the next token, reporting the end of the source after previous
+func (c *octoCompiler) next(previous octoToken) (octoToken, error) {
+	if c.pos >= len(c.tokens) {
+		return octoToken{}, c.errorf(previous, "unexpected end of source after %s", previous.text)
+	}
+	token := c.tokens[c.pos]
+	c.pos++
+	return token, nil
+}
+
+func (c *octoCompiler) peek() string {
+	if c.pos < len(c.tokens) {
+		return c.tokens[c.pos].text
+	}
+	return ""
+}
+
+// start : Compile a jump to main at 0x200, unless main comes before anything else
+func (c *octoCompiler) start(token octoToken, label string) {
+	if c.started {
+		return
+	}
+	c.started = true
+	if label != "main" {
+		here := c.here
+		c.here = 0x200
+		c.fixups = append(c.fixups, octoFixup{addr: c.here, label: "main", token: token})
+		c.emit(token, 0x10, 0x00)
+		if here > c.here {
+			c.here = here
+		}
+	}
+}
+
+// emit : Compile bytes at here
+func (c *octoCompiler) emit(token octoToken, data ...byte) error {
+	if c.here+len(data) > len(c.memory) {
+		return c.errorf(token, "program too large")
+	}
+	copy(c.memory[c.here:], data)
+	c.here += len(data)
+	if c.here > c.end {
+		c.end = c.here
+	}
+	return nil
+}
+
+// instruction : Compile an instruction, recording its source line
+func (c *octoCompiler) instruction(token octoToken, opcodes ...uint16) error {
+	for _, opcode := range opcodes {
+		c.symbols.Lines = append(c.symbols.Lines, SourceLine{Addr: uint16(c.here), File: c.file, Line: token.line})
+		if err := c.emit(token, byte(opcode>>8), byte(opcode)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// register : Parse a register name or alias, v0-vf
+func (c *octoCompiler) register(token octoToken) (uint16, bool) {
+	if x, ok := c.aliases[token.text]; ok {
+		return uint16(x), true
+	}
+	return vRegister(token.text)
+}
+
+func (c *octoCompiler) nextRegister(previous octoToken) (uint16, octoToken, error) {
+	token, err := c.next(previous)
+	if err != nil {
+		return 0, token, err
+	}
+	x, ok := c.register(token)
+	if !ok {
+		return 0, token, c.errorf(token, "expected a register, got: %s", token.text)
+	}
+	return x, token, nil
+}
+
+// number : Value of a number, constant or defined label
+func (c *octoCompiler) number(token octoToken) (float64, bool) {
+	if v, ok := c.consts[token.text]; ok {
+		return v, true
+	}
+	if addr, ok := c.labels[token.text]; ok {
+		return float64(addr), true
+	}
+	text := token.text
+	negative := strings.HasPrefix(text, "-")
+	if negative {
+		text = text[1:]
+	}
+	if text == "" || text[0] < '0' || text[0] > '9' {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(text, 0, 32)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		v = -v
+	}
+	return float64(v), true
+}
+
+// value : Next token as a number within [min, max], with negative bytes as two's complement
+func (c *octoCompiler) value(previous octoToken, min, max int) (int, octoToken, error) {
+	token, err := c.next(previous)
+	if err != nil {
+		return 0, token, err
+	}
+	v, ok := c.number(token)
+	if !ok {
+		return 0, token, c.errorf(token, "expected a number, got: %s", token.text)
+	}
+	n := int(v)
+	if n < min || n > max {
+		return 0, token, c.errorf(token, "%s out of range: %d", token.text, n)
+	}
+	if max == 0xFF && n < 0 {
+		n += 0x100
+	}
+	return n, token, nil
+}
+
+// address : Next token as an address operand of opcode, filled in later if it is a label not yet defined
+func (c *octoCompiler) address(previous octoToken, opcode uint16) error {
+	token, err := c.next(previous)
+	if err != nil {
+		return err
+	}
+	if v, ok := c.number(token); ok {
+		if v < 0 || v > 0xFFF {
+			return c.errorf(token, "address out of range: %s", token.text)
+		}
+		return c.instruction(previous, opcode|uint16(v))
+	}
+	if !isIdentifier(token.text) {
+		return c.errorf(token, "expected an address, got: %s", token.text)
+	}
+	c.fixups = append(c.fixups, octoFixup{addr: c.here, label: token.text, token: token})
+	return c.instruction(previous, opcode)
+}
+
+// block : Tokens between { and the matching }
+func (c *octoCompiler) block(previous octoToken) ([]octoToken, error) {
+	open, err := c.next(previous)
+	if err != nil {
+		return nil, err
+	}
+	if open.text != "{" {
+		return nil, c.errorf(open, "expected {, got: %s", open.text)
+	}
+	var body []octoToken
+	for depth := 1; ; {
+		token, err := c.next(open)
+		if err != nil {
+			return nil, err
+		}
+		switch token.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+		if depth == 0 {
+			return body, nil
+		}
+		body = append(body, token)
+	}
+}
+
+// name : Next token as a new name
+func (c *octoCompiler) name(previous octoToken) (octoToken, error) {
+	token, err := c.next(previous)
+	if err != nil {
+		return token, err
+	}
+	if !isOctoName(token.text) {
+		return token, c.errorf(token, "bad name: %s", token.text)
+	}
+	if _, ok := vRegister(token.text); ok {
+		return token, c.errorf(token, "%s is a register", token.text)
+	}
+	return token, nil
+}
+
+func isOctoName(s string) bool {
+	if s == "" || s[0] >= '0' && s[0] <= '9' || s[0] == '-' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isAlphanumeric(s[i]) && !strings.ContainsRune("_-.", rune(s[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// statement : Compile the next statement or directive
+func (c *octoCompiler) statement() error {
+	token := c.tokens[c.pos]
+	c.pos++
+
+	if macro, ok := c.macros[token.text]; ok {
+		return c.expand(token, macro)
+	}
+	switch token.text {
+	case ":", ":alias", ":const", ":calc", ":macro", ":org", ":breakpoint", ":monitor":
+	default:
+		c.start(token, "")
+	}
+	if x, ok := c.register(token); ok {
+		return c.assignment(token, x)
+	}
+
+	switch token.text {
+	case ":":
+		name, err := c.name(token)
+		if err != nil {
+			return err
+		}
+		if _, ok := c.labels[name.text]; ok {
+			return c.errorf(name, "label %s already defined", name.text)
+		}
+		c.start(name, name.text)
+		c.labels[name.text] = c.here
+		return nil
+	case ":alias":
+		name, err := c.name(token)
+		if err != nil {
+			return err
+		}
+		x, _, err := c.nextRegister(name)
+		c.aliases[name.text] = int(x)
+		return err
+	case ":const":
+		name, err := c.name(token)
+		if err != nil {
+			return err
+		}
+		value, err := c.next(name)
+		if err != nil {
+			return err
+		}
+		v, ok := c.number(value)
+		if !ok {
+			return c.errorf(value, "expected a number, got: %s", value.text)
+		}
+		c.consts[name.text] = v
+		return nil
+	case ":calc":
+		name, err := c.name(token)
+		if err != nil {
+			return err
+		}
+		body, err := c.block(name)
+		if err != nil {
+			return err
+		}
+		v, err := c.calc(name, body)
+		c.consts[name.text] = v
+		return err
+	case ":macro":
+		name, err := c.name(token)
+		if err != nil {
+			return err
+		}
+		macro := &octoMacro{}
+		for c.peek() != "{" && c.peek() != "" {
+			param, _ := c.next(name)
+			macro.params = append(macro.params, param.text)
+		}
+		if macro.body, err = c.block(name); err != nil {
+			return err
+		}
+		c.macros[name.text] = macro
+		return nil
+	case ":byte":
+		var v int
+		var err error
+		if c.peek() == "{" {
+			var body []octoToken
+			if body, err = c.block(token); err != nil {
+				return err
+			}
+			calc, err := c.calc(token, body)
+			if err != nil {
+				return err
+			}
+			v = int(calc) & 0xFF
+		} else if v, _, err = c.value(token, -128, 0xFF); err != nil {
+			return err
+		}
+		return c.emit(token, byte(v))
+	case ":pointer":
+		v, _, err := c.value(token, 0, 0xFFFF)
+		if err != nil {
+			return err
+		}
+		return c.emit(token, byte(v>>8), byte(v))
+	case ":org":
+		v, _, err := c.value(token, 0x200, 0xFFFF)
+		c.here = v
+		return err
+	case ":unpack":
+		nibble, _, err := c.value(token, 0, 0xF)
+		if err != nil {
+			return err
+		}
+		// v0 and v1 get the nibble and a 12 bit address
+		target, err := c.next(token)
+		if err != nil {
+			return err
+		}
+		addr := 0
+		if v, ok := c.number(target); ok && v >= 0 && v <= 0xFFF {
+			addr = int(v)
+		} else if !ok && isOctoName(target.text) {
+			c.fixups = append(c.fixups, octoFixup{addr: c.here, kind: "unpack", label: target.text, token: target})
+		} else {
+			return c.errorf(target, "expected an address, got: %s", target.text)
+		}
+		return c.instruction(token, 0x6000|uint16(nibble)<<4|uint16(addr)>>8, 0x6100|uint16(addr)&0xFF)
+	case ":breakpoint":
+		_, err := c.next(token)
+		return err
+	case ":monitor":
+		if _, err := c.next(token); err != nil {
+			return err
+		}
+		_, err := c.next(token)
+		return err
+	case ";", "return":
+		return c.instruction(token, 0x00EE)
+	case "clear":
+		return c.instruction(token, 0x00E0)
+	case "scroll-right":
+		return c.instruction(token, 0x00FB)
+	case "scroll-left":
+		return c.instruction(token, 0x00FC)
+	case "exit":
+		return c.instruction(token, 0x00FD)
+	case "lores":
+		return c.instruction(token, 0x00FE)
+	case "hires":
+		return c.instruction(token, 0x00FF)
+	case "audio":
+		return c.instruction(token, 0xF002)
+	case "scroll-down", "scroll-up", "plane":
+		n, _, err := c.value(token, 0, 0xF)
+		if err != nil {
+			return err
+		}
+		opcode := map[string]uint16{"scroll-down": 0x00C0, "scroll-up": 0x00D0, "plane": 0xF001}[token.text]
+		if token.text == "plane" {
+			return c.instruction(token, opcode|uint16(n)<<8)
+		}
+		return c.instruction(token, opcode|uint16(n))
+	case "jump":
+		return c.address(token, 0x1000)
+	case "jump0":
+		return c.address(token, 0xB000)
+	case "native":
+		return c.address(token, 0x0000)
+	case "sprite":
+		x, _, err := c.nextRegister(token)
+		if err != nil {
+			return err
+		}
+		y, _, err := c.nextRegister(token)
+		if err != nil {
+			return err
+		}
+		n, _, err := c.value(token, 0, 0xF)
+		if err != nil {
+			return err
+		}
+		return c.instruction(token, 0xD000|x<<8|y<<4|uint16(n))
+	case "bcd", "saveflags", "loadflags":
+		x, _, err := c.nextRegister(token)
+		if err != nil {
+			return err
+		}
+		kk := map[string]uint16{"bcd": 0x33, "saveflags": 0x75, "loadflags": 0x85}[token.text]
+		return c.instruction(token, 0xF000|x<<8|kk)
+	case "save", "load":
+		x, _, err := c.nextRegister(token)
+		if err != nil {
+			return err
+		}
+		if c.peek() == "-" {
+			c.pos++
+			y, _, err := c.nextRegister(token)
+			if err != nil {
+				return err
+			}
+			opcode := uint16(0x5002)
+			if token.text == "load" {
+				opcode = 0x5003
+			}
+			return c.instruction(token, opcode|x<<8|y<<4)
+		}
+		kk := uint16(0x55)
+		if token.text == "load" {
+			kk = 0x65
+		}
+		return c.instruction(token, 0xF000|x<<8|kk)
+	case "delay", "buzzer", "pitch":
+		op, err := c.next(token)
+		if err != nil {
+			return err
+		}
+		if op.text != ":=" {
+			return c.errorf(op, "expected :=, got: %s", op.text)
+		}
+		x, _, err := c.nextRegister(op)
+		if err != nil {
+			return err
+		}
+		kk := map[string]uint16{"delay": 0x15, "buzzer": 0x18, "pitch": 0x3A}[token.text]
+		return c.instruction(token, 0xF000|x<<8|kk)
+	case "i":
+		return c.indexAssignment(token)
+	case "if":
+		return c.conditional(token)
+	case "else":
+		if len(c.controls) == 0 || c.controls[len(c.controls)-1].kind != "if" {
+			return c.errorf(token, "else without if ... begin")
+		}
+		open := &c.controls[len(c.controls)-1]
+		jump := c.here
+		if err := c.instruction(token, 0x1000); err != nil {
+			return err
+		}
+		c.patchJump(open.jump, c.here)
+		open.kind, open.jump = "else", jump
+		return nil
+	case "end":
+		if len(c.controls) == 0 || c.controls[len(c.controls)-1].kind == "loop" {
+			return c.errorf(token, "end without if ... begin")
+		}
+		c.patchJump(c.controls[len(c.controls)-1].jump, c.here)
+		c.controls = c.controls[:len(c.controls)-1]
+		return nil
+	case "loop":
+		c.controls = append(c.controls, octoControl{kind: "loop", token: token, start: c.here})
+		return nil
+	case "while":
+		loop := -1
+		for i := len(c.controls) - 1; i >= 0 && loop < 0; i-- {
+			if c.controls[i].kind == "loop" {
+				loop = i
+			}
+		}
+		if loop < 0 {
+			return c.errorf(token, "while outside a loop")
+		}
+		_, skipIfTrue, err := c.condition(token)
+		if err != nil {
+			return err
+		}
+		c.controls[loop].whiles = append(c.controls[loop].whiles, c.here+2)
+		return c.instruction(token, skipIfTrue, 0x1000)
+	case "again":
+		if len(c.controls) == 0 || c.controls[len(c.controls)-1].kind != "loop" {
+			return c.errorf(token, "again without loop")
+		}
+		loop := c.controls[len(c.controls)-1]
+		c.controls = c.controls[:len(c.controls)-1]
+		if err := c.instruction(token, 0x1000|uint16(loop.start)); err != nil {
+			return err
+		}
+		for _, jump := range loop.whiles {
+			c.patchJump(jump, c.here)
+		}
+		return nil
+	}
+
+	if v, ok := c.number(token); ok {
+		if _, isLabel := c.labels[token.text]; !isLabel {
+			if v < -128 || v > 0xFF {
+				return c.errorf(token, "byte out of range: %s", token.text)
+			}
+			return c.emit(token, byte(int(v)))
+		}
+	}
+	if isOctoName(token.text) && !strings.HasPrefix(token.text, ":") {
+		// a call to a subroutine
+		c.pos--
+		return c.address(token, 0x2000)
+	}
+	return c.errorf(token, "unknown statement: %s", token.text)
+}
+
+func (c *octoCompiler) patchJump(addr, target int) {
+	c.memory[addr] = 0x10 | byte(target>>8)
+	c.memory[addr+1] = byte(target)
+}
+
+// expand : Insert a macro's body, with its parameters replaced by the following tokens
+func (c *octoCompiler) expand(token octoToken, macro *octoMacro) error {
+	args := make(map[string]string)
+	for _, param := range macro.params {
+		arg, err := c.next(token)
+		if err != nil {
+			return err
+		}
+		args[param] = arg.text
+	}
+	body := make([]octoToken, len(macro.body))
+	for i, t := range macro.body {
+		if arg, ok := args[t.text]; ok {
+			t.text = arg
+		}
+		body[i] = t
+	}
+	if len(c.tokens)+len(body) > 1<<20 {
+		return c.errorf(token, "macro expansion too large, is %s recursive?", token.text)
+	}
+	c.tokens = append(c.tokens[:c.pos], append(body, c.tokens[c.pos:]...)...)
+	return nil
+}
+
+// assignment : Compile a statement starting with register x
+func (c *octoCompiler) assignment(token octoToken, x uint16) error {
+	op, err := c.next(token)
+	if err != nil {
+		return err
+	}
+	rhs, err := c.next(op)
+	if err != nil {
+		return err
+	}
+	x <<= 8
+	if y, ok := c.register(rhs); ok {
+		n, ok := map[string]uint16{":=": 0, "|=": 1, "&=": 2, "^=": 3, "+=": 4, "-=": 5, ">>=": 6, "=-": 7, "<<=": 0xE}[op.text]
+		if !ok {
+			return c.errorf(op, "unknown operator: %s", op.text)
+		}
+		return c.instruction(token, 0x8000|x|y<<4|n)
+	}
+	switch {
+	case op.text == ":=" && rhs.text == "key":
+		return c.instruction(token, 0xF00A|x)
+	case op.text == ":=" && rhs.text == "delay":
+		return c.instruction(token, 0xF007|x)
+	case op.text == ":=" && rhs.text == "random":
+		kk, _, err := c.value(rhs, 0, 0xFF)
+		if err != nil {
+			return err
+		}
+		return c.instruction(token, 0xC000|x|uint16(kk))
+	case op.text == ":=", op.text == "+=", op.text == "-=":
+		c.pos--
+		kk, _, err := c.value(op, -128, 0xFF)
+		if err != nil {
+			return err
+		}
+		switch op.text {
+		case ":=":
+			return c.instruction(token, 0x6000|x|uint16(kk))
+		case "+=":
+			return c.instruction(token, 0x7000|x|uint16(kk))
+		}
+		return c.instruction(token, 0x7000|x|uint16(-kk)&0xFF)
+	}
+	return c.errorf(op, "unknown operator: %s %s", op.text, rhs.text)
+}
+
+// indexAssignment : Compile a statement assigning to or adding to i
+func (c *octoCompiler) indexAssignment(token octoToken) error {
+	op, err := c.next(token)
+	if err != nil {
+		return err
+	}
+	switch op.text {
+	case "+=":
+		x, _, err := c.nextRegister(op)
+		if err != nil {
+			return err
+		}
+		return c.instruction(token, 0xF01E|x<<8)
+	case ":=":
+	default:
+		return c.errorf(op, "expected := or +=, got: %s", op.text)
+	}
+	switch c.peek() {
+	case "hex", "bighex":
+		kind, _ := c.next(op)
+		x, _, err := c.nextRegister(kind)
+		if err != nil {
+			return err
+		}
+		if kind.text == "hex" {
+			return c.instruction(token, 0xF029|x<<8)
+		}
+		return c.instruction(token, 0xF030|x<<8)
+	case "long":
+		long, _ := c.next(op)
+		target, err := c.next(long)
+		if err != nil {
+			return err
+		}
+		addr := 0
+		if v, ok := c.number(target); ok {
+			addr = int(v)
+		} else if isOctoName(target.text) {
+			c.fixups = append(c.fixups, octoFixup{addr: c.here, kind: "long", label: target.text, token: target})
+		} else {
+			return c.errorf(target, "expected an address, got: %s", target.text)
+		}
+		if addr < 0 || addr > 0xFFFF {
+			return c.errorf(target, "address out of range: %s", target.text)
+		}
+		c.symbols.Lines = append(c.symbols.Lines, SourceLine{Addr: uint16(c.here), File: c.file, Line: token.line})
+		return c.emit(token, 0xF0, 0x00, byte(addr>>8), byte(addr))
+	}
+	return c.address(op, 0xA000)
+}
+
+// conditional : Compile if ... then, or the start of if ... begin
+func (c *octoCompiler) conditional(token octoToken) error {
+	skipIfFalse, skipIfTrue, err := c.condition(token)
+	if err != nil {
+		return err
+	}
+	kind, err := c.next(token)
+	if err != nil {
+		return err
+	}
+	switch kind.text {
+	case "then":
+		return c.instruction(token, skipIfFalse)
+	case "begin":
+		c.controls = append(c.controls, octoControl{kind: "if", token: token, jump: c.here + 2})
+		return c.instruction(token, skipIfTrue, 0x1000)
+	}
+	return c.errorf(kind, "expected then or begin, got: %s", kind.text)
+}
+
+// condition : Compile any setup for a condition, returning the skips of the next instruction
+// when it is false and when it is true. Comparisons use VF.
+func (c *octoCompiler) condition(token octoToken) (uint16, uint16, error) {
+	x, lhs, err := c.nextRegister(token)
+	if err != nil {
+		return 0, 0, err
+	}
+	op, err := c.next(lhs)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch op.text {
+	case "key":
+		return 0xE0A1 | x<<8, 0xE09E | x<<8, nil
+	case "-key":
+		return 0xE09E | x<<8, 0xE0A1 | x<<8, nil
+	}
+	rhs, err := c.next(op)
+	if err != nil {
+		return 0, 0, err
+	}
+	y, isRegister := c.register(rhs)
+	var kk uint16
+	if !isRegister {
+		c.pos--
+		v, _, err := c.value(op, -128, 0xFF)
+		if err != nil {
+			return 0, 0, err
+		}
+		kk = uint16(v)
+	}
+
+	switch op.text {
+	case "==", "!=":
+		equal, notEqual := 0x3000|x<<8|kk, 0x4000|x<<8|kk // SE, SNE
+		if isRegister {
+			equal, notEqual = 0x5000|x<<8|y<<4, 0x9000|x<<8|y<<4
+		}
+		if op.text == "==" {
+			return notEqual, equal, nil
+		}
+		return equal, notEqual, nil
+	case "<", ">=", ">", "<=":
+	default:
+		return 0, 0, c.errorf(op, "unknown comparison: %s", op.text)
+	}
+	// VF can't be either operand, it's overwritten by the carry before the result is read
+	switch {
+	case x == 0xF:
+		return 0, 0, c.errorf(lhs, "vf cannot be compared with %s, it holds the result", op.text)
+	case isRegister && y == 0xF:
+		return 0, 0, c.errorf(rhs, "vf cannot be compared with %s, it holds the result", op.text)
+	}
+
+	// VF is set to a carry without using VF as a destination, which interpreters disagree on,
+	// and the registers compared are restored afterwards
+	var whenTrue uint16 // VF when the condition is true
+	switch {
+	case isRegister:
+		// v -= w; v += w carries when v < w
+		v, w := x, y
+		if op.text == ">" || op.text == "<=" {
+			v, w = y, x
+		}
+		err = c.instruction(token, 0x8005|v<<8|w<<4, 0x8004|v<<8|w<<4)
+		whenTrue = uint16(truth(op.text == "<" || op.text == ">"))
+	default:
+		// vf := 256 - n; x += vf carries when x >= n, then x += n restores x
+		n := int(kk)
+		if op.text == ">" || op.text == "<=" {
+			n++
+		}
+		switch n {
+		case 0:
+			err = c.instruction(token, 0x6F01)
+		case 0x100:
+			err = c.instruction(token, 0x6F00)
+		default:
+			err = c.instruction(token, 0x6F00|uint16(0x100-n), 0x80F4|x<<8, 0x7000|x<<8|uint16(n))
+		}
+		whenTrue = uint16(truth(op.text == ">=" || op.text == ">"))
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return 0x4F00 | whenTrue, 0x3F00 | whenTrue, nil
+}
+
+// calc : Value of a :calc expression, evaluated right to left with no operator precedence as in Octo
+func (c *octoCompiler) calc(name octoToken, tokens []octoToken) (float64, error) {
+	pos := 0
+	var term func() (float64, error)
+	var expr func() (float64, error)
+	binary := map[string]func(a, b float64) float64{
+		"+":   func(a, b float64) float64 { return a + b },
+		"-":   func(a, b float64) float64 { return a - b },
+		"*":   func(a, b float64) float64 { return a * b },
+		"/":   func(a, b float64) float64 { return a / b },
+		"%":   func(a, b float64) float64 { return math.Mod(a, b) },
+		"&":   func(a, b float64) float64 { return float64(int(a) & int(b)) },
+		"|":   func(a, b float64) float64 { return float64(int(a) | int(b)) },
+		"^":   func(a, b float64) float64 { return float64(int(a) ^ int(b)) },
+		"<<":  func(a, b float64) float64 { return float64(int(a) << uint(b)) },
+		">>":  func(a, b float64) float64 { return float64(int(a) >> uint(b)) },
+		"pow": math.Pow,
+		"min": math.Min,
+		"max": math.Max,
+		"<":   func(a, b float64) float64 { return float64(truth(a < b)) },
+		">":   func(a, b float64) float64 { return float64(truth(a > b)) },
+		"<=":  func(a, b float64) float64 { return float64(truth(a <= b)) },
+		">=":  func(a, b float64) float64 { return float64(truth(a >= b)) },
+		"==":  func(a, b float64) float64 { return float64(truth(a == b)) },
+		"!=":  func(a, b float64) float64 { return float64(truth(a != b)) },
+	}
+	unary := map[string]func(a float64) float64{
+		"-":     func(a float64) float64 { return -a },
+		"~":     func(a float64) float64 { return float64(^int(a)) },
+		"!":     func(a float64) float64 { return float64(truth(a == 0)) },
+		"abs":   math.Abs,
+		"floor": math.Floor,
+		"ceil":  math.Ceil,
+		"sqrt":  math.Sqrt,
+		"sin":   math.Sin,
+		"cos":   math.Cos,
+	}
+	term = func() (float64, error) {
+		if pos >= len(tokens) {
+			return 0, c.errorf(name, "unexpected end of expression")
+		}
+		token := tokens[pos]
+		pos++
+		if f, ok := unary[token.text]; ok {
+			v, err := term()
+			return f(v), err
+		}
+		switch token.text {
+		case "(":
+			v, err := expr()
+			if err != nil {
+				return 0, err
+			}
+			if pos >= len(tokens) || tokens[pos].text != ")" {
+				return 0, c.errorf(token, "missing )")
+			}
+			pos++
+			return v, nil
+		case "HERE":
+			return float64(c.here), nil
+		case "PI":
+			return math.Pi, nil
+		}
+		if v, ok := c.number(token); ok {
+			return v, nil
+		}
+		return 0, c.errorf(token, "unknown value: %s", token.text)
+	}
+	expr = func() (float64, error) {
+		left, err := term()
+		if err != nil || pos >= len(tokens) {
+			return left, err
+		}
+		f, ok := binary[tokens[pos].text]
+		if !ok {
+			return left, nil
+		}
+		pos++
+		right, err := expr()
+		return f(left, right), err
+	}
+	v, err := expr()
+	if err == nil && pos < len(tokens) {
+		return 0, c.errorf(tokens[pos], "unexpected %s in expression", tokens[pos].text)
+	}
+	return v, err
+}
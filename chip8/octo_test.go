@@ -0,0 +1,139 @@
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func compileOctoString(t *testing.T, source string) ([]byte, *Symbols) {
+	t.Helper()
+	rom, symbols, err := CompileOcto("test.8o", strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rom, symbols
+}
+
+func TestCompileOcto(t *testing.T) {
+	rom, symbols := compileOctoString(t, `
+:alias x v0
+:const SIZE 5
+:calc WIDTH { SIZE * 2 + 1 } # right to left, so 15
+: main
+	clear
+	i := box
+	x := WIDTH
+	v1 := -1
+	v1 += 1
+	sprite x v1 SIZE
+	if v1 == 3 then v2 := key
+	draw
+	i := long box
+	loop
+		v1 -= 2
+		while v1 != 0
+	again
+	jump main
+: draw
+	v1 >>= v2
+	;
+: box
+	0b11111000 0x88 136 0x88 0xF8
+`)
+	want := []byte{
+		0x00, 0xE0,
+		0xA2, 0x24,
+		0x60, 0x0F,
+		0x61, 0xFF,
+		0x71, 0x01,
+		0xD0, 0x15,
+		0x41, 0x03, 0xF2, 0x0A,
+		0x22, 0x20,
+		0xF0, 0x00, 0x02, 0x24,
+		0x71, 0xFE,
+		0x41, 0x00, 0x12, 0x1E,
+		0x12, 0x16,
+		0x12, 0x00,
+		0x81, 0x26,
+		0x00, 0xEE,
+		0xF8, 0x88, 0x88, 0x88, 0xF8,
+	}
+	if !bytes.Equal(rom, want) {
+		t.Errorf("Expected ROM incorrect, got:\n% x\nwant:\n% x", rom, want)
+	}
+	if addr, ok := symbols.LabelAddr("draw"); !ok || addr != 0x220 {
+		t.Errorf("Expected draw at 0x220, got: 0x%x", addr)
+	}
+	if line, ok := symbols.Line(0x212); !ok || line.File != "test.8o" || line.Line != 14 {
+		t.Errorf("Expected i := long on line 14, got: %v", line)
+	}
+
+	// main not first gets a jump to it
+	rom, _ = compileOctoString(t, ": helper ;\n: main helper")
+	if want := []byte{0x12, 0x04, 0x00, 0xEE, 0x22, 0x02}; !bytes.Equal(rom, want) {
+		t.Errorf("Expected jump to main incorrect, got: % x, want: % x", rom, want)
+	}
+}
+
+func TestCompileOctoRun(t *testing.T) {
+	rom, symbols := compileOctoString(t, `
+:macro add-twice reg n { reg += n reg += n }
+: main
+	v0 := 0
+	v1 := 0
+	loop
+		v0 += 1
+		if v0 > 5 begin
+			v1 += 1
+		else
+			v1 += 2
+		end
+		while v0 < 8
+	again
+	v2 := 10
+	if v2 >= v0 then v3 := 1
+	if v0 <= 7 then v4 := 1
+	add-twice v5 3
+	v6 := v0
+	v7 := v1
+	:unpack 0xA data
+	loop again
+: data
+`)
+	vm := runHalt(rom)
+
+	data, _ := symbols.LabelAddr("data")
+	want := []uint8{0xA0 | uint8(data>>8), uint8(data), 10, 1, 0, 6, 8, 13}
+	got := []uint8{vm.V(0), vm.V(1), vm.V(2), vm.V(3), vm.V(4), vm.V(5), vm.V(6), vm.V(7)}
+	if vm.Halted() != HaltSelfJump || !bytes.Equal(got, want) {
+		t.Errorf("Expected registers incorrect, got: %s % x, want: % x", vm.Halted(), got, want)
+	}
+}
+
+func TestCompileOctoErrors(t *testing.T) {
+	cases := []struct {
+		source       string
+		line, column int
+	}{
+		{"v0 := 256", 1, 7},
+		{": main\n  jump nowhere", 2, 8},
+		{": main\nif v0 == 1 begin\n  clear", 2, 1},
+		{"v0 @= v1", 1, 4},
+		{":calc x { 1 + }", 1, 7},
+		{": main\n\tagain", 2, 2},
+		{": main\n  sprite v0 i 5", 2, 13},
+		{": main\n  if vf > v1 then v0 := 1", 2, 6},
+		{": main\n  if vf < 3 then v0 := 1", 2, 6},
+		{": main\n  if v1 < vf then v0 := 1", 2, 11},
+		{": main\n  if v1 >= vF then v0 := 1", 2, 12},
+	}
+	for _, c := range cases {
+		_, _, err := CompileOcto("bad.8o", strings.NewReader(c.source))
+		var octoErr *OctoError
+		if !errors.As(err, &octoErr) || octoErr.Line != c.line || octoErr.Column != c.column {
+			t.Errorf("Expected an error at %d:%d for %q, got: %v", c.line, c.column, c.source, err)
+		}
+	}
+}
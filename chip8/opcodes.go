@@ -0,0 +1,598 @@
+package chip8
+
+import (
+	"math"
+)
+
+// instructionLength : Length in bytes of the instruction at addr, 4 for XO-CHIP F000 nnnn long loads
+func (vm *Machine) instructionLength(addr uint16) uint16 {
+	if vm.platform == PlatformXOChip && vm.memory[addr] == 0xF0 && vm.memory[addr+1] == 0x00 {
+		return 4
+	}
+	return 2
+}
+
+// skip : Skip the next instruction, XO-CHIP F000 nnnn long loads are skipped as a whole
+func (vm *Machine) skip() {
+	vm.pc += 2
+	vm.pc += vm.instructionLength(vm.pc)
+}
+
+// registerRange : Registers x to y of the current opcode in order, x may be greater than y
+func (vm *Machine) registerRange() []uint16 {
+	x := vm.opcode & 0x0F00 >> 8
+	y := vm.opcode & 0x00F0 >> 4
+	regs := []uint16{x}
+	for r := x; r != y; {
+		if x < y {
+			r++
+		} else {
+			r--
+		}
+		regs = append(regs, r)
+	}
+	return regs
+}
+
+// playbackRate : XO-CHIP audio pattern playback rate in bits per second
+func (vm *Machine) playbackRate() float64 {
+	return 4000 * math.Pow(2, (float64(vm.pitch)-64)/48)
+}
+
+// schipOpcode : Whether an opcode was added by Super CHIP-8, so isn't known on the chip-8 platform
+func schipOpcode(opcode uint16) bool {
+	switch {
+	case opcode&0xFFF0 == 0x00C0, opcode >= 0x00FB && opcode <= 0x00FF:
+		return true
+	case opcode&0xF0FF == 0xF030, opcode&0xF0FF == 0xF075, opcode&0xF0FF == 0xF085:
+		return true
+	}
+	return false
+}
+
+// execute : Fetch and execute the instruction at vm.pc, returns false if the program exited
+// Faulting instructions return a fault before changing any state.
+func (vm *Machine) execute() (bool, *Fault) {
+	var running bool
+	if fault := vm.checkMemory(vm.pc, 2); fault != nil {
+		return true, fault
+	}
+	vm.opcode = uint16(vm.memory[vm.pc])<<8 | uint16(vm.memory[vm.pc+1]) // big-endian
+	vm.drawflag = false
+	if vm.pc == 0x200 && vm.opcode == 0x1260 && vm.vipHires != "off" {
+		// Two-page hires ROMs start by jumping to an interpreter patch at 0x260,
+		// instead enable the 64x64 mode and jump straight to the program at 0x2C0
+		vm.setVIPHires()
+		vm.opcode = 0x12C0
+	}
+	if vm.platform == PlatformChip8 && schipOpcode(vm.opcode) {
+		return true, vm.newFault(FaultUnknownOpcode, 0)
+	}
+	switch vm.opcode & 0xF000 {
+	case 0x0000:
+		switch vm.opcode & 0x00FF {
+		case 0x00E0:
+			// clear vm.screen
+			// fmt.Printf("Clear vm.screen % x, % d\n", vm.opcode, vm.pc)
+			vm.clearPlanes()
+			vm.drawflag = true
+			vm.pc += 2
+		case 0x00EE:
+			// 00EE - RET
+			// Return from a subroutine.
+			if vm.sp <= 0 {
+				return true, vm.newFault(FaultStackUnderflow, 0)
+			}
+			// fmt.Printf("RET pc: %x, new pc: %x, opcode: %x\n", vm.pc, vm.stack[vm.sp]+2, vm.opcode)
+			vm.sp--
+			vm.pc = vm.stack[vm.sp] + 2
+			vm.stack = vm.stack[:vm.sp]
+			// fmt.Printf("RET pc: %d\n", vm.pc)
+		case 0x00FB:
+			// 00FB - SCR (Super CHIP-8)
+			// Scroll display right by 4 pixels.
+			vm.scrollRight()
+			vm.drawflag = true
+			vm.pc += 2
+		case 0x00FC:
+			// 00FC - SCL (Super CHIP-8)
+			// Scroll display left by 4 pixels.
+			vm.scrollLeft()
+			vm.drawflag = true
+			vm.pc += 2
+		case 0x00FD:
+			// 00FD - EXIT (Super CHIP-8)
+			// Exit the interpreter.
+			vm.halt = HaltExit
+			return false, nil
+		case 0x00FE:
+			// 00FE - LOW (Super CHIP-8)
+			// Disable high resolution mode (64x32).
+			vm.setHires(false)
+			vm.pc += 2
+		case 0x00FF:
+			// 00FF - HIGH (Super CHIP-8)
+			// Enable high resolution mode (128x64).
+			vm.setHires(true)
+			vm.pc += 2
+		default:
+			if vm.opcode&0xFFF0 == 0x00C0 {
+				// 00Cn - SCD nibble (Super CHIP-8)
+				// Scroll display down n lines.
+				vm.scrollDown(uint8(vm.opcode & 0x000F))
+				vm.drawflag = true
+			} else if vm.opcode == 0x0230 && vm.width == 64 && vm.height == 64 {
+				// 0230 - CLS (COSMAC VIP two-page hires)
+				// Clear the 64x64 display.
+				vm.clearPlanes()
+				vm.drawflag = true
+			} else if vm.opcode&0xFFF0 == 0x00D0 && vm.platform == PlatformXOChip {
+				// 00Dn - SCU nibble (XO-CHIP)
+				// Scroll display up n lines.
+				vm.scrollUp(uint8(vm.opcode & 0x000F))
+				vm.drawflag = true
+			}
+			// fmt.Printf("SYS vm.opcode ignored: % x, % d\n", vm.opcode, vm.pc)
+			vm.pc += 2
+		}
+	case 0x1000:
+		// 1nnn - JP addr
+		// Jump to location nnn.
+		if 0x0FFF&vm.opcode < 0x200 {
+			return true, vm.newFault(FaultIllegalJump, 0x0FFF&vm.opcode)
+		}
+		if 0x0FFF&vm.opcode == vm.pc {
+			// endless jumps are used as halt
+			vm.halt = HaltSelfJump
+			return false, nil
+		}
+		vm.pc = 0x0FFF & vm.opcode
+		// fmt.Printf("JMP to : % x, % x\n", vm.pc, vm.opcode)
+
+	case 0x2000:
+		// 2nnn - CALL addr
+		// Call subroutine at nnn.
+		if 0x0FFF&vm.opcode < 0x200 {
+			return true, vm.newFault(FaultIllegalJump, 0x0FFF&vm.opcode)
+		}
+		if vm.stackDepth != StackUnlimited && int(vm.sp) >= vm.stackDepth {
+			return true, vm.newFault(FaultStackOverflow, 0)
+		}
+		vm.stack = append(vm.stack[:vm.sp], vm.pc)
+		vm.sp++
+		// fmt.Printf("CALL pc: %x, new pc: %x, opcode: %x\n", vm.pc, (0x0FFF & vm.opcode), vm.opcode)
+		vm.pc = 0x0FFF & vm.opcode
+		// fmt.Printf("CALL stack: %s\n", fmt.Sprint(vm.stack))
+
+	case 0x3000:
+		// 3xkk - SE vm.Vx, byte
+		// Skip next instruction if vm.Vx = kk.
+		if vm.v[0x0F00&vm.opcode>>8] == uint8(0x00FF&vm.opcode) {
+			vm.skip()
+		} else {
+			vm.pc += 2
+		}
+	case 0x4000:
+		// 4xkk - SNE vm.Vx, byte
+		// Skip next instruction if vm.Vx != kk.
+		if vm.v[0x0F00&vm.opcode>>8] != uint8(0x00FF&vm.opcode) {
+			vm.skip()
+		} else {
+			vm.pc += 2
+		}
+	case 0x5000:
+		switch {
+		case vm.opcode&0x000F == 0x0002 && vm.platform == PlatformXOChip:
+			// 5xy2 - SAVE vm.Vx - vm.Vy (XO-CHIP)
+			// Store registers vm.Vx through vm.Vy in vm.memory starting at location vm.i, vm.i is unchanged.
+			if fault := vm.accessMemory(vm.i, len(vm.registerRange()), true); fault != nil {
+				return true, fault
+			}
+			for i, r := range vm.registerRange() {
+				vm.memory[vm.i+uint16(i)] = vm.v[r]
+			}
+			vm.pc += 2
+		case vm.opcode&0x000F == 0x0003 && vm.platform == PlatformXOChip:
+			// 5xy3 - LOAD vm.Vx - vm.Vy (XO-CHIP)
+			// Read registers vm.Vx through vm.Vy from vm.memory starting at location vm.i, vm.i is unchanged.
+			if fault := vm.accessMemory(vm.i, len(vm.registerRange()), false); fault != nil {
+				return true, fault
+			}
+			for i, r := range vm.registerRange() {
+				vm.v[r] = vm.memory[vm.i+uint16(i)]
+			}
+			vm.pc += 2
+		default:
+			// 5xy0 - SE vm.Vx, vm.Vy
+			// Skip next instruction if vm.Vx = vm.Vy.
+			if vm.v[0x0F00&vm.opcode>>8] == vm.v[uint8(0x00F0&vm.opcode)>>4] {
+				vm.skip()
+			} else {
+				vm.pc += 2
+			}
+		}
+
+	case 0x6000:
+		// 6xkk - LD vm.Vx, byte
+		// Set vm.Vx = kk.
+		vm.v[0x0F00&vm.opcode>>8] = uint8(0x00FF & vm.opcode)
+		vm.pc += 2
+
+	case 0x7000:
+		// 7xkk - ADD vm.Vx, byte
+		// Set vm.Vx = vm.Vx + kk.
+		vm.v[0x0F00&vm.opcode>>8] += uint8(0x0FF & vm.opcode)
+		vm.pc += 2
+
+	case 0x8000:
+		switch vm.opcode & 0x000F {
+		case 0x0000:
+			//8xy0 - LD vm.Vx, vm.Vy
+			//Set vm.Vx = vm.Vy.
+			vm.v[vm.opcode&0x0F00>>8] = vm.v[vm.opcode&0x00F0>>4]
+			vm.pc += 2
+		case 0x0001:
+			// 8xy1 - OR vm.Vx, vm.Vy
+			// Set vm.Vx = vm.Vx OR vm.Vy.
+			vm.v[vm.opcode&0x0F00>>8] |= vm.v[vm.opcode&0x00F0>>4]
+			if vm.quirks.VFReset {
+				vm.v[0xF] = 0
+			}
+			vm.pc += 2
+		case 0x0002:
+			// 8xy2 - AND vm.Vx, vm.Vy
+			// Set vm.Vx = vm.Vx AND vm.Vy.
+			vm.v[vm.opcode&0x0F00>>8] &= vm.v[vm.opcode&0x00F0>>4]
+			if vm.quirks.VFReset {
+				vm.v[0xF] = 0
+			}
+			vm.pc += 2
+		case 0x0003:
+			// 8xy3 - XOR vm.Vx, vm.Vy
+			// Set vm.Vx = vm.Vx XOR vm.Vy.
+			vm.v[vm.opcode&0x0F00>>8] ^= vm.v[vm.opcode&0x00F0>>4]
+			if vm.quirks.VFReset {
+				vm.v[0xF] = 0
+			}
+			vm.pc += 2
+		case 0x0004:
+			// 8xy4 - ADD vm.Vx, vm.Vy
+			// Set vm.Vx = vm.Vx + vm.Vy, set vm.VF = carry.
+			tempvar := uint16(vm.v[vm.opcode&0x0F00>>8]) + uint16(vm.v[vm.opcode&0x00F0>>4])
+			vm.v[vm.opcode&0x0F00>>8] = uint8(0x00FF & tempvar)
+			if 0xFF00&tempvar != 0 {
+				vm.v[0xF] = 1
+			} else {
+				vm.v[0xF] = 0
+			}
+			vm.pc += 2
+		case 0x0005:
+			// 8xy5 - SUB vm.Vx, vm.Vy
+			// Set vm.Vx = vm.Vx - vm.Vy, set vm.VF = NOT borrow.
+			if vm.v[vm.opcode&0x0F00>>8] > vm.v[vm.opcode&0x00F0>>4] {
+				vm.v[0xF] = 1
+			} else {
+				vm.v[0xF] = 0
+			}
+			vm.pc += 2
+			vm.v[vm.opcode&0x0F00>>8] -= vm.v[vm.opcode&0x00F0>>4]
+		case 0x0006:
+			// 8xy6 - SHR vm.Vx {, vm.Vy}
+			// Set vm.Vx = vm.Vx SHR 1, or vm.Vy SHR 1 without the shift quirk.
+			src := vm.v[vm.opcode&0x0F00>>8]
+			if !vm.quirks.Shift {
+				src = vm.v[vm.opcode&0x00F0>>4]
+			}
+			vm.v[vm.opcode&0x0F00>>8] = src >> 1
+			vm.v[0xF] = src & 1
+			vm.pc += 2
+		case 0x0007:
+			// 8xy7 - SUBN vm.Vx, vm.Vy
+			// Set vm.Vx = vm.Vy - vm.Vx, set vm.VF = NOT borrow.
+			if vm.v[vm.opcode&0x00F0>>4] > vm.v[vm.opcode&0x0F00>>8] {
+				vm.v[0xF] = 1
+			} else {
+				vm.v[0xF] = 0
+			}
+			vm.pc += 2
+			vm.v[vm.opcode&0x0F00>>8] = vm.v[vm.opcode&0x00F0>>4] - vm.v[vm.opcode&0x0F00>>8]
+		case 0x000E:
+			// 8xyE - SHL vm.Vx {, vm.Vy}
+			// Set vm.Vx = vm.Vx SHL 1, or vm.Vy SHL 1 without the shift quirk.
+			src := vm.v[vm.opcode&0x0F00>>8]
+			if !vm.quirks.Shift {
+				src = vm.v[vm.opcode&0x00F0>>4]
+			}
+			vm.v[vm.opcode&0x0F00>>8] = src << 1
+			vm.v[0xF] = src >> 7
+			vm.pc += 2
+		default:
+			return true, vm.newFault(FaultUnknownOpcode, 0)
+		}
+
+	case 0x9000:
+		// 9xy0 - SNE vm.Vx, vm.Vy
+		// Skip next instruction if vm.Vx != vm.Vy.
+		if vm.v[0x0F00&vm.opcode>>8] != vm.v[0x00F0&vm.opcode>>4] {
+			vm.skip()
+		} else {
+			vm.pc += 2
+		}
+
+	case 0xA000:
+		// Annn - LD vm.i, addr
+		// Set vm.i = nnn.
+		vm.i = vm.opcode & 0x0FFF
+		vm.pc += 2
+		// fmt.Printf("% x\n", vm.i)
+
+	case 0xB000:
+		// Bnnn - JP vm.V0, addr
+		// Jump to location nnn + vm.V0, or xnn + vm.Vx with the jump quirk.
+		if vm.quirks.Jump {
+			vm.pc = 0x0FFF&vm.opcode + uint16(vm.v[0x0F00&vm.opcode>>8])
+		} else {
+			vm.pc = 0x0FFF&vm.opcode + uint16(vm.v[0])
+		}
+
+	case 0xC000:
+		// Cxkk - RND vm.Vx, byte
+		// Set vm.Vx = random byte AND kk.
+		vm.v[0x0F00&vm.opcode>>8] = uint8(vm.random()>>56) & uint8(0x00FF&vm.opcode)
+		vm.pc += 2
+
+	case 0xD000:
+		// Dxyn - DRW vm.Vx, vm.Vy, nibble
+		// Display n-byte sprite starting at vm.memory location vm.i at (vm.Vx, vm.Vy), set vm.VF = collision.
+		// fmt.Printf("Draw sprite + collide: % x, %d\n", vm.opcode, vm.pc)
+
+		vm.drawflag = true
+		n := 0x000F & vm.opcode
+		x := vm.v[0x0F00&vm.opcode>>8]
+		y := vm.v[0x00F0&vm.opcode>>4]
+		if x >= vm.width {
+			switch vm.wrapX {
+			case "on", "clip":
+				x = 0 + x%vm.width
+			case "off":
+				vm.drawflag = false
+			case "error":
+				return true, vm.newFault(FaultDrawOutOfBounds, 0)
+			}
+		}
+		if y >= vm.height {
+			switch vm.wrapY {
+			case "on", "clip":
+				y = 0 + y%vm.height
+			case "off":
+				vm.drawflag = false
+			case "error":
+				return true, vm.newFault(FaultDrawOutOfBounds, 0)
+			}
+		}
+		if vm.drawflag {
+			if fault := vm.accessMemory(vm.i, vm.spriteSize(n), false); fault != nil {
+				return true, fault
+			}
+		}
+		vm.v[0xF] = 0
+		vm.pc += 2
+		if vm.quirks.DisplayWait {
+			vm.vblankWait = true
+		}
+		if vm.drawflag {
+			if n == 0 {
+				// Dxy0 - DRW Vx, Vy, 0 (Super CHIP-8)
+				// Display 16x16 sprite, stored as two bytes per row.
+				vm.drawSprite(x, y, 16, 16)
+			} else {
+				vm.drawSprite(x, y, 8, n)
+			}
+		}
+		// The interpreter reads n bytes from vm.memory, starting at the address stored in vm.i.
+		// These bytes are then displayed as sprites on vm.screen at coordinates (vm.Vx, vm.Vy).
+		// Sprites are XORed onto the existing vm.screen.
+		// If this causes any pixels to be erased, vm.VF is set to 1, otherwise it is set to 0.
+		// If the sprite is positioned so part of it is outside the coordinates of the display,
+		// it wraps around to the opposite side of the vm.screen.
+
+	case 0xE000:
+		switch vm.opcode & 0x00FF {
+		case 0x009E:
+			// Ex9E - SKP vm.Vx
+			// Skip next instruction if key with the value of vm.Vx is pressed.
+			if vm.keyPressed(vm.v[0x0F00&vm.opcode>>8]) {
+				vm.skip()
+			} else {
+				vm.pc += 2
+			}
+
+		case 0x00A1:
+			// ExA1 - SKNP vm.Vx
+			// Skip next instruction if key with the value of vm.Vx is not pressed.
+			if !vm.keyPressed(vm.v[0x0F00&vm.opcode>>8]) {
+				vm.skip()
+			} else {
+				vm.pc += 2
+			}
+
+		default:
+			return true, vm.newFault(FaultUnknownOpcode, 0)
+		}
+
+	case 0xF000:
+		switch vm.opcode & 0x00FF {
+		case 0x0000:
+			// F000 nnnn - LD vm.i, long addr (XO-CHIP)
+			// Set vm.i = nnnn, the 16-bit address in the following two bytes.
+			if vm.opcode != 0xF000 || vm.platform != PlatformXOChip {
+				return true, vm.newFault(FaultUnknownOpcode, 0)
+			}
+			if fault := vm.checkMemory(vm.pc, 4); fault != nil {
+				return true, fault
+			}
+			vm.i = uint16(vm.memory[vm.pc+2])<<8 | uint16(vm.memory[vm.pc+3])
+			vm.pc += 4
+
+		case 0x0001:
+			// Fn01 - PLANE n (XO-CHIP)
+			// Select the bitplanes n for drawing, clearing and scrolling.
+			if vm.platform != PlatformXOChip {
+				return true, vm.newFault(FaultUnknownOpcode, 0)
+			}
+			vm.planes = uint8(0x0F00&vm.opcode>>8) & 0x3
+			vm.pc += 2
+
+		case 0x0002:
+			// F002 - AUDIO (XO-CHIP)
+			// Load the 16 byte audio pattern buffer from vm.memory starting at location vm.i.
+			if vm.opcode != 0xF002 || vm.platform != PlatformXOChip {
+				return true, vm.newFault(FaultUnknownOpcode, 0)
+			}
+			if fault := vm.accessMemory(vm.i, 16, false); fault != nil {
+				return true, fault
+			}
+			for i := uint16(0); i < 16; i++ {
+				vm.pattern[i] = vm.memory[vm.i+i]
+			}
+			vm.pc += 2
+
+		case 0x0007:
+			// Fx07 - LD vm.Vx, DT
+			// Set vm.Vx = delay timer value.
+			vm.v[0x0F00&vm.opcode>>8] = vm.delayTimer
+			vm.pc += 2
+
+		case 0x000A:
+			// Fx0A - LD vm.Vx, K
+			// Wait for a key press, store the value of the key in vm.Vx.
+			var key uint8
+			var pressed bool
+			key, pressed, running = vm.waitForKey()
+			if !running {
+				vm.halt = HaltKeyboard
+				return false, nil
+			}
+			if !pressed {
+				return true, nil // run Fx0A again until a key is pressed
+			}
+			vm.v[0x0F00&vm.opcode>>8] = key
+			vm.pc += 2
+
+		case 0x0015:
+			// Fx15 - LD DT, vm.Vx
+			// Set delay timer = vm.Vx.
+			vm.delayTimer = vm.v[0x0F00&vm.opcode>>8]
+			vm.pc += 2
+
+		case 0x0018:
+			// Fx18 - LD ST, vm.Vx
+			// Set sound timer = vm.Vx.
+			vm.soundTimer = vm.v[0x0F00&vm.opcode>>8]
+			vm.pc += 2
+
+		case 0x001E:
+			// Fx1E - ADD vm.i, vm.Vx
+			// Set vm.i = vm.i + vm.Vx.
+			vm.i += uint16(vm.v[0x0F00&vm.opcode>>8])
+			vm.pc += 2
+
+		case 0x0029:
+			// Fx29 - LD F, vm.Vx
+			// Set vm.i = location of sprite for digit vm.Vx.
+			// The value of vm.i is set to the location for the hexadecimal sprite corresponding to the value of vm.Vx.
+
+			vm.i = 0x050 + 5*uint16(vm.v[0x0F00&vm.opcode>>8])
+			vm.pc += 2
+
+		case 0x0030:
+			// Fx30 - LD HF, vm.Vx (Super CHIP-8)
+			// Set vm.i = location of 8x10 sprite for digit vm.Vx.
+			vm.i = 0x0A0 + 10*uint16(vm.v[0x0F00&vm.opcode>>8]&0xF)
+			vm.pc += 2
+
+		case 0x0033:
+			// Fx33 - LD B, vm.Vx
+			// Store BCD representation of vm.Vx in vm.memory locations vm.i, vm.i+1, and vm.i+2.
+			// The interpreter takes the decimal value of vm.Vx,
+			// and places the hundreds digit in vm.memory at location in vm.i,
+			// the tens digit at location vm.i+1,
+			// and the ones digit at location vm.i+2.
+			if fault := vm.accessMemory(vm.i, 3, true); fault != nil {
+				return true, fault
+			}
+			vm.memory[vm.i] = vm.v[0x0F00&vm.opcode>>8] / 100
+			vm.memory[vm.i+1] = (vm.v[0x0F00&vm.opcode>>8] - vm.memory[vm.i]*100) / 10
+			vm.memory[vm.i+2] = vm.v[0x0F00&vm.opcode>>8] - vm.memory[vm.i]*100 - vm.memory[vm.i+1]*10
+
+			vm.pc += 2
+
+		case 0x0055:
+			// Fx55 - LD [vm.i], vm.Vx
+			// Store registers vm.V0 through vm.Vx in vm.memory starting at location vm.i.
+			// vm.i is incremented by x+1 without the load/store quirk.
+			if fault := vm.accessMemory(vm.i, int(0x0F00&vm.opcode>>8)+1, true); fault != nil {
+				return true, fault
+			}
+			var i uint16
+			for i = 0; i <= 0x0F00&vm.opcode>>8; i++ {
+				vm.memory[vm.i+i] = vm.v[i]
+			}
+			if !vm.quirks.LoadStore {
+				vm.i += i
+			}
+			vm.pc += 2
+
+		case 0x0065:
+			// Fx65 - LD vm.Vx, [vm.i]
+			// Read registers vm.V0 through vm.Vx from vm.memory starting at location vm.i.
+			// vm.i is incremented by x+1 without the load/store quirk.
+			if fault := vm.accessMemory(vm.i, int(0x0F00&vm.opcode>>8)+1, false); fault != nil {
+				return true, fault
+			}
+			var i uint16
+			for i = 0; i <= 0x0F00&vm.opcode>>8; i++ {
+				vm.v[i] = vm.memory[vm.i+i]
+			}
+			if !vm.quirks.LoadStore {
+				vm.i += i
+			}
+			vm.pc += 2
+
+		case 0x003A:
+			// Fx3A - PITCH vm.Vx (XO-CHIP)
+			// Set the audio pattern playback pitch = vm.Vx.
+			if vm.platform != PlatformXOChip {
+				return true, vm.newFault(FaultUnknownOpcode, 0)
+			}
+			vm.pitch = vm.v[0x0F00&vm.opcode>>8]
+			vm.pc += 2
+
+		case 0x0075:
+			// Fx75 - LD R, vm.Vx (Super CHIP-8)
+			// Store registers vm.V0 through vm.Vx in the RPL user flags.
+			var i uint16
+			for i = 0; i <= 0x0F00&vm.opcode>>8; i++ {
+				vm.rpl[i] = vm.v[i]
+			}
+			vm.pc += 2
+
+		case 0x0085:
+			// Fx85 - LD vm.Vx, R (Super CHIP-8)
+			// Read registers vm.V0 through vm.Vx from the RPL user flags.
+			var i uint16
+			for i = 0; i <= 0x0F00&vm.opcode>>8; i++ {
+				vm.v[i] = vm.rpl[i]
+			}
+			vm.pc += 2
+
+		default:
+			return true, vm.newFault(FaultUnknownOpcode, 0)
+		}
+
+	default:
+		return true, vm.newFault(FaultUnknownOpcode, 0)
+	}
+	return true, nil
+
+}
@@ -0,0 +1,38 @@
+package chip8
+
+// Quirks : Behaviours that differ between CHIP-8 variants
+type Quirks struct {
+	Shift       bool // 8xy6/8xyE shift Vx in place, rather than shifting Vy into Vx
+	LoadStore   bool // Fx55/Fx65 leave I unchanged, rather than incrementing it by x+1
+	Jump        bool // Bxnn jumps to xnn + Vx, rather than nnn + V0
+	VFReset     bool // 8xy1/8xy2/8xy3 reset VF to 0
+	Clip        bool // sprites are clipped at the screen edges, rather than wrapped
+	DisplayWait bool // Dxyn waits for the next 60Hz vertical blank before continuing
+}
+
+// QuirksPresets : Named quirks profiles
+var QuirksPresets = map[string]Quirks{
+	// Original COSMAC VIP interpreter
+	"vip": {VFReset: true, Clip: true, DisplayWait: true},
+	// Super CHIP-8 1.1 on the HP48
+	"schip-legacy": {Shift: true, LoadStore: true, Jump: true, Clip: true, DisplayWait: true},
+	// Super CHIP-8 as implemented by most modern interpreters
+	"schip-modern": {Shift: true, LoadStore: true, Jump: true, Clip: true},
+	// XO-CHIP as implemented by Octo
+	"xo-chip": {},
+}
+
+// DefaultQuirks : chip8go's original behaviour, used by default for the chip-8 platform
+var DefaultQuirks = Quirks{Shift: true, LoadStore: true}
+
+// PlatformQuirks : Default quirks for a platform when no preset is given
+func PlatformQuirks(platform string) Quirks {
+	switch platform {
+	case PlatformSCHIP:
+		return QuirksPresets["schip-modern"]
+	case PlatformXOChip:
+		return QuirksPresets["xo-chip"]
+	default:
+		return DefaultQuirks
+	}
+}
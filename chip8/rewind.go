@@ -0,0 +1,186 @@
+package chip8
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Rewind : Buffer of recent frames for stepping backwards through play
+// Only the latest frame is kept whole, older frames are stored as compressed differences
+// to the frame after them, which are usually a few dozen bytes.
+type Rewind struct {
+	vm     *Machine
+	latest []byte   // snapshot of the last recorded frame
+	deltas [][]byte // ring buffer of differences to the previous frame, oldest first from next-count
+	next   int      // index of the next delta to record
+	count  int
+	size   int // bytes used by the deltas
+}
+
+// rewindRegisters : Fixed size part of a rewind snapshot, followed by the memory and stack
+type rewindRegisters struct {
+	PC, I, Opcode, SP      uint16
+	V                      [16]uint8
+	Screen                 [2][64][16]uint8
+	Width, Height          uint8
+	Hires                  bool
+	Planes                 uint8
+	RPL                    [16]uint8
+	Pattern                [16]uint8
+	Pitch                  uint8
+	DelayTimer, SoundTimer uint8
+	VBlankWait             bool
+	Cycles                 uint64
+	Frames                 uint64
+	RNG                    uint64
+}
+
+// NewRewind : Rewind buffer holding up to frames frames of vm
+func NewRewind(vm *Machine, frames int) *Rewind {
+	if frames < 1 {
+		frames = 1
+	}
+	return &Rewind{vm: vm, deltas: make([][]byte, frames)}
+}
+
+// Record : Add the current state of the machine, called once per frame
+// The oldest frame is dropped when the buffer is full.
+func (rewind *Rewind) Record() {
+	snapshot := rewind.vm.snapshot()
+	if rewind.latest != nil {
+		if rewind.count == len(rewind.deltas) {
+			oldest := (rewind.next - rewind.count + len(rewind.deltas)) % len(rewind.deltas)
+			rewind.size -= len(rewind.deltas[oldest])
+			rewind.deltas[oldest] = nil
+			rewind.count--
+		}
+		delta := xorDelta(rewind.latest, snapshot)
+		rewind.deltas[rewind.next] = delta
+		rewind.size += len(delta)
+		rewind.next = (rewind.next + 1) % len(rewind.deltas)
+		rewind.count++
+	}
+	rewind.latest = snapshot
+}
+
+// Back : Restore the machine to the previous recorded frame, false when there is none left
+func (rewind *Rewind) Back() bool {
+	if rewind.count == 0 {
+		return false
+	}
+	rewind.next = (rewind.next - 1 + len(rewind.deltas)) % len(rewind.deltas)
+	delta := rewind.deltas[rewind.next]
+	rewind.deltas[rewind.next] = nil
+	rewind.size -= len(delta)
+	rewind.count--
+	rewind.latest = applyDelta(rewind.latest, delta)
+	rewind.vm.restoreSnapshot(rewind.latest)
+	return true
+}
+
+// Frames : Number of frames that can be stepped back
+func (rewind *Rewind) Frames() int { return rewind.count }
+
+// Size : Approximate memory used by the buffer in bytes
+func (rewind *Rewind) Size() int { return rewind.size + len(rewind.latest) }
+
+// Clear : Drop all recorded frames
+func (rewind *Rewind) Clear() {
+	for i := range rewind.deltas {
+		rewind.deltas[i] = nil
+	}
+	rewind.latest = nil
+	rewind.next, rewind.count, rewind.size = 0, 0, 0
+}
+
+// snapshot : Machine state as bytes, laid out the same for every frame so they compare well
+func (vm *Machine) snapshot() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, rewindRegisters{
+		PC: vm.pc, I: vm.i, Opcode: vm.opcode, SP: vm.sp, V: vm.v,
+		Screen: vm.screen, Width: vm.width, Height: vm.height, Hires: vm.hires, Planes: vm.planes,
+		RPL: vm.rpl, Pattern: vm.pattern, Pitch: vm.pitch,
+		DelayTimer: vm.delayTimer, SoundTimer: vm.soundTimer,
+		VBlankWait: vm.vblankWait, Cycles: vm.cycles, Frames: vm.frames, RNG: vm.rng,
+	})
+	buf.Write(vm.memory[:vm.memSize])
+	binary.Write(&buf, binary.LittleEndian, vm.stack)
+	return buf.Bytes()
+}
+
+// restoreSnapshot : Set the machine to a snapshot, keeping its quirks
+func (vm *Machine) restoreSnapshot(snapshot []byte) {
+	r := bytes.NewReader(snapshot)
+	var regs rewindRegisters
+	binary.Read(r, binary.LittleEndian, &regs)
+	memory := make([]uint8, vm.memSize)
+	r.Read(memory)
+	stack := make([]uint16, r.Len()/2)
+	binary.Read(r, binary.LittleEndian, stack)
+
+	vm.restoreState(&machineState{
+		VIPHires: vm.vipHires, Quirks: vm.quirks, WrapX: vm.wrapX, WrapY: vm.wrapY, StackDepth: vm.stackDepth,
+		PC: regs.PC, I: regs.I, Opcode: regs.Opcode, SP: regs.SP, V: regs.V,
+		Memory: memory, Screen: regs.Screen, Width: regs.Width, Height: regs.Height, Hires: regs.Hires,
+		Planes: regs.Planes, RPL: regs.RPL, Pattern: regs.Pattern, Pitch: regs.Pitch,
+		DelayTimer: regs.DelayTimer, SoundTimer: regs.SoundTimer, Stack: stack,
+		VBlankWait: regs.VBlankWait, Cycles: regs.Cycles, Frames: regs.Frames, RNG: regs.RNG,
+	})
+}
+
+// xorDelta : Difference that turns to back into from, as the length of from followed by runs of
+// unchanged bytes and changed bytes XORed with to: (unchanged count, changed count, changed bytes)...
+func xorDelta(from, to []byte) []byte {
+	n := len(from)
+	if len(to) > n {
+		n = len(to)
+	}
+	at := func(b []byte, i int) byte {
+		if i < len(b) {
+			return b[i]
+		}
+		return 0
+	}
+	delta := binary.AppendUvarint(nil, uint64(len(from)))
+	for i := 0; i < n; {
+		start := i
+		for i < n && at(from, i) == at(to, i) {
+			i++
+		}
+		delta = binary.AppendUvarint(delta, uint64(i-start))
+		start = i
+		for i < n && at(from, i) != at(to, i) {
+			i++
+		}
+		delta = binary.AppendUvarint(delta, uint64(i-start))
+		for j := start; j < i; j++ {
+			delta = append(delta, at(from, j)^at(to, j))
+		}
+	}
+	return delta
+}
+
+// applyDelta : Recover from given to and xorDelta(from, to)
+func applyDelta(to []byte, delta []byte) []byte {
+	length, k := binary.Uvarint(delta)
+	delta = delta[k:]
+	n := len(to)
+	if int(length) > n {
+		n = int(length)
+	}
+	from := make([]byte, n)
+	copy(from, to)
+	for i := 0; len(delta) > 0; {
+		same, k := binary.Uvarint(delta)
+		delta = delta[k:]
+		changed, k := binary.Uvarint(delta)
+		delta = delta[k:]
+		i += int(same)
+		for j := 0; j < int(changed); j++ {
+			from[i+j] ^= delta[j]
+		}
+		i += int(changed)
+		delta = delta[changed:]
+	}
+	return from[:length]
+}
@@ -0,0 +1,61 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRewind(t *testing.T) {
+	vm := New(Config{CyclesPerFrame: 3})
+	if err := vm.LoadROM(stateROM); err != nil {
+		t.Fatal(err)
+	}
+	rewind := NewRewind(vm, 5)
+	var frames []Machine
+	for f := 0; f < 8; f++ {
+		rewind.Record()
+		frames = append(frames, *vm)
+		vm.SkipFrame()
+	}
+	rewind.Record()
+
+	if rewind.Frames() != 5 {
+		t.Errorf("Expected rewind frames incorrect, got: %d, want: 5", rewind.Frames())
+	}
+	for f := 7; f >= 3; f-- {
+		if !rewind.Back() {
+			t.Fatalf("Rewind to frame %d failed", f)
+		}
+		want := frames[f]
+		if vm.pc != want.pc || vm.v != want.v || vm.sp != want.sp || vm.screen != want.screen || vm.cycles != want.cycles {
+			t.Errorf("Frame %d incorrect, got PC: 0x%x V0: %d SP: %d, want PC: 0x%x V0: %d SP: %d",
+				f, vm.pc, vm.v[0], vm.sp, want.pc, want.v[0], want.sp)
+		}
+	}
+	if rewind.Back() {
+		t.Errorf("Expected rewind past the oldest frame to fail")
+	}
+
+	// play continues from the rewound frame
+	vm.SkipFrame()
+	rewind.Record()
+	if rewind.Frames() != 1 || !rewind.Back() || vm.pc != frames[3].pc || vm.v != frames[3].v {
+		t.Errorf("Expected rewind after resuming incorrect, got PC: 0x%x V0: %d", vm.pc, vm.v[0])
+	}
+}
+
+func TestXORDelta(t *testing.T) {
+	cases := [][2][]byte{
+		{{1, 2, 3, 4}, {1, 2, 3, 4}},
+		{{1, 2, 3, 4}, {1, 9, 3, 8}},
+		{{1, 2}, {1, 2, 0, 5}},
+		{{1, 2, 0, 5}, {7, 2}},
+		{nil, {1}},
+	}
+	for _, c := range cases {
+		from, to := c[0], c[1]
+		if got := applyDelta(to, xorDelta(from, to)); !bytes.Equal(got, from) {
+			t.Errorf("Delta from %v to %v incorrect, got: %v", from, to, got)
+		}
+	}
+}
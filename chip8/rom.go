@@ -1,48 +1,43 @@
-package main
+package chip8
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
-	"io/ioutil"
 	"strings"
 )
 
-func check(e error) {
-	if e != nil {
-		panic(e)
-	}
-}
-
-func reverseMap(m map[uint16]uint8) map[uint8]uint16 {
-	n := make(map[uint8]uint16)
-	for k, v := range m {
-		n[v] = k
-	}
-	return n
+// ROMHash : Hex encoded SHA-1 of the ROM bytes, identifies ROMs in the ROM database and save states
+func ROMHash(rom []byte) string {
+	sum := sha1.Sum(rom)
+	return hex.EncodeToString(sum[:])
 }
 
-func charToHex(c rune) byte {
+func charToHex(c rune) (byte, error) {
 	switch {
 	case c >= 48 && c <= 57:
-		return byte(c - 48)
+		return byte(c - 48), nil
 	case c >= 65 && c <= 70:
-		return byte(c - 55)
+		return byte(c - 55), nil
 	default:
-		panic(fmt.Sprint("Bad Hex character: %i", c))
+		return 0, fmt.Errorf("bad hex character: %q", c)
 	}
 }
 
 // ROMFromString : Convert string of form "00 00 00" to a list of bytes
-func ROMFromString(s string) []byte {
+func ROMFromString(s string) ([]byte, error) {
 	var out []byte
 	var curbyte byte
-	var tempbyte byte
 
 	s = strings.ToUpper(s)
 	s = strings.Replace(s, " ", "", -1)
 	s = strings.Replace(s, "\n", "", -1)
 
 	for i, byt := range s {
-		tempbyte = charToHex(byt)
+		tempbyte, err := charToHex(byt)
+		if err != nil {
+			return nil, err
+		}
 
 		if i%2 == 1 {
 			curbyte <<= 4
@@ -52,7 +47,7 @@ func ROMFromString(s string) []byte {
 			curbyte = tempbyte
 		}
 	}
-	return out
+	return out, nil
 }
 
 // PrintROM : Print list of bytes in "00 00" form
@@ -67,9 +62,3 @@ func PrintROM(rom []byte) {
 		}
 	}
 }
-
-func readROM(filename string) []byte {
-	dat, err := ioutil.ReadFile(filename)
-	check(err)
-	return dat
-}
@@ -1,4 +1,4 @@
-package main
+package chip8
 
 import "testing"
 import "fmt"
@@ -6,7 +6,10 @@ import "fmt"
 func TestROMFromString(t *testing.T) {
 	// ADD Vx
 	var romarray [4]byte
-	rombytes := ROMFromString("a2cc 6a06")
+	rombytes, err := ROMFromString("a2cc 6a06")
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	copy(romarray[:], rombytes)
 	if romarray != [4]byte{0xA2, 0xCC, 0x6A, 0x06} {
@@ -14,3 +17,9 @@ func TestROMFromString(t *testing.T) {
 			fmt.Sprint(romarray), fmt.Sprint([4]byte{0xA2, 0xCC, 0x6A, 0x06}))
 	}
 }
+
+func TestROMFromStringBadHex(t *testing.T) {
+	if _, err := ROMFromString("a2cg"); err == nil {
+		t.Errorf("ROMFromString accepted a bad hex character")
+	}
+}
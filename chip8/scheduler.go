@@ -0,0 +1,63 @@
+package chip8
+
+import "time"
+
+// Scheduler : Runs a Machine in real time, one frame per timer tick
+// Frame deadlines are kept on the wall clock, so timing doesn't drift with host load or sleep granularity.
+type Scheduler struct {
+	vm      *Machine
+	period  time.Duration
+	maxSkip int
+	next    time.Time // deadline of the next frame
+	skipped int
+	now     func() time.Time
+	sleep   func(time.Duration)
+}
+
+// NewScheduler : Scheduler running vm at its TimerSpeed frames per second
+// When running behind, up to maxSkip frames are run without rendering to catch up before the lost time is dropped.
+func NewScheduler(vm *Machine, maxSkip int) *Scheduler {
+	return &Scheduler{
+		vm:      vm,
+		period:  time.Second / time.Duration(vm.timerSpeed),
+		maxSkip: maxSkip,
+		now:     time.Now,
+		sleep:   time.Sleep,
+	}
+}
+
+// Reset : Restart timing from now, e.g. after pausing, so the time spent isn't caught up
+func (scheduler *Scheduler) Reset() {
+	scheduler.next = time.Time{}
+}
+
+// Skipped : Number of frames run without rendering to catch up
+func (scheduler *Scheduler) Skipped() int {
+	return scheduler.skipped
+}
+
+// Tick : Wait for the next frame deadline and run the frames that are due, rendering the last one
+// Returns false once the program has stopped, faults are returned as by RunFrame.
+func (scheduler *Scheduler) Tick() (bool, error) {
+	if scheduler.next.IsZero() {
+		scheduler.next = scheduler.now()
+	}
+	if wait := scheduler.next.Sub(scheduler.now()); wait > 0 {
+		scheduler.sleep(wait)
+	}
+	// catch up on any further frames that are already due
+	for skipped := 0; scheduler.now().Sub(scheduler.next) >= scheduler.period; skipped++ {
+		if skipped >= scheduler.maxSkip {
+			// too far behind, drop the time rather than running fast
+			scheduler.next = scheduler.now()
+			break
+		}
+		scheduler.next = scheduler.next.Add(scheduler.period)
+		scheduler.skipped++
+		if running, err := scheduler.vm.SkipFrame(); !running || err != nil {
+			return running, err
+		}
+	}
+	scheduler.next = scheduler.next.Add(scheduler.period)
+	return scheduler.vm.RunFrame()
+}
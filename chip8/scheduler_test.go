@@ -0,0 +1,66 @@
+package chip8
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock : Clock that only moves when slept or advanced
+type fakeClock struct {
+	t time.Time
+}
+
+func (clock *fakeClock) now() time.Time          { return clock.t }
+func (clock *fakeClock) sleep(d time.Duration)   { clock.t = clock.t.Add(d) }
+func (clock *fakeClock) advance(d time.Duration) { clock.t = clock.t.Add(d) }
+func returnScheduler(maxSkip int) (*Scheduler, *fakeClock) {
+	// count instructions in V0 and V1 (V0 overflows into V1), with a long delay timer
+	rombytes := []byte{0x6F, 0xFF, 0xFF, 0x15, 0x70, 0x01, 0x30, 0x00, 0x12, 0x04, 0x71, 0x01, 0x12, 0x04}
+	vm := New(Config{CyclesPerFrame: 10})
+	vm.LoadROM(rombytes)
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	scheduler := NewScheduler(vm, maxSkip)
+	scheduler.now = clock.now
+	scheduler.sleep = clock.sleep
+	return scheduler, clock
+}
+
+func TestSchedulerTick(t *testing.T) {
+	scheduler, clock := returnScheduler(5)
+	start := clock.t
+	for i := 0; i < 60; i++ {
+		scheduler.Tick()
+	}
+
+	// 60 frames take a second of wall-clock time, ticking the timer once each
+	if elapsed := clock.t.Sub(start); elapsed != 59*(time.Second/60) {
+		t.Errorf("Expected elapsed time incorrect, got: %s", elapsed)
+	}
+	if scheduler.vm.delayTimer != 0xFF-60 || scheduler.Skipped() != 0 {
+		t.Errorf("Expected delay timer incorrect, got: %d, skipped: %d", scheduler.vm.delayTimer, scheduler.Skipped())
+	}
+}
+
+func TestSchedulerCatchUp(t *testing.T) {
+	scheduler, clock := returnScheduler(5)
+	scheduler.Tick()
+
+	// a slow host loses three frames, which are run without rendering
+	clock.advance(4 * time.Second / 60)
+	scheduler.Tick()
+	if scheduler.vm.delayTimer != 0xFF-5 || scheduler.Skipped() != 3 {
+		t.Errorf("Expected delay timer incorrect, got: %d, skipped: %d", scheduler.vm.delayTimer, scheduler.Skipped())
+	}
+
+	// a stall longer than the frame skip limit is dropped
+	clock.advance(time.Second)
+	scheduler.Tick()
+	if scheduler.vm.delayTimer != 0xFF-11 || scheduler.Skipped() != 8 {
+		t.Errorf("Expected delay timer incorrect, got: %d, skipped: %d", scheduler.vm.delayTimer, scheduler.Skipped())
+	}
+	before := clock.t
+	scheduler.Tick()
+	if clock.t.Sub(before) != time.Second/60 {
+		t.Errorf("Expected frame time after a stall incorrect, got: %s", clock.t.Sub(before))
+	}
+}
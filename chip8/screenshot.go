@@ -0,0 +1,31 @@
+package chip8
+
+import (
+	"image"
+	"image/color"
+)
+
+// Screenshot : Image of the screen at scale times its resolution, with a colour for each palette index
+// The palette is the background, the foreground, the XO-CHIP second plane and both planes.
+func (vm *Machine) Screenshot(palette [4]color.Color, scale int) *image.Paletted {
+	if scale < 1 {
+		scale = 1
+	}
+	width, height := vm.Resolution()
+	img := image.NewPaletted(image.Rect(0, 0, width*scale, height*scale), palette[:])
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			colour := vm.Pixel(x, y)
+			if colour == 0 {
+				continue
+			}
+			for row := y * scale; row < (y+1)*scale; row++ {
+				start := img.PixOffset(x*scale, row)
+				for i := start; i < start+scale; i++ {
+					img.Pix[i] = colour
+				}
+			}
+		}
+	}
+	return img
+}
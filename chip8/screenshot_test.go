@@ -0,0 +1,33 @@
+package chip8
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestScreenshot(t *testing.T) {
+	// LD F, V0; DRW V0, V0, 5; JP 0x204
+	vm := runHalt([]byte{0xF0, 0x29, 0xD0, 0x05, 0x12, 0x04})
+	palette := [4]color.Color{color.Black, color.White, color.Gray{0x80}, color.Gray{0x40}}
+
+	img := vm.Screenshot(palette, 3)
+	if bounds := img.Bounds(); bounds.Dx() != 64*3 || bounds.Dy() != 32*3 {
+		t.Fatalf("Expected size incorrect, got: %v", bounds)
+	}
+	// the top row of the 0 is 0xF0
+	for x := 0; x < 8; x++ {
+		want := color.Color(color.White)
+		if x >= 4 {
+			want = color.Black
+		}
+		for _, p := range [][2]int{{x * 3, 0}, {x*3 + 2, 2}} {
+			if got := img.At(p[0], p[1]); got != img.Palette.Convert(want) {
+				t.Errorf("Expected pixel (%d, %d) incorrect, got: %v", p[0], p[1], got)
+			}
+		}
+	}
+	// the second row is 0x90
+	if got := img.At(3, 3); got != img.Palette.Convert(color.Black) {
+		t.Errorf("Expected pixel (3, 3) unset, got: %v", got)
+	}
+}
@@ -0,0 +1,188 @@
+package chip8
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// StateVersion : Version of the save state format written by SaveState
+// Version 2 added the frame count and the random number generator. Version 1 states still load,
+// counting frames from 0 and restarting the generator from the machine's Config.Seed.
+const StateVersion = 2
+
+// stateMagic : Identifies save state files, followed by the version as a big-endian uint16
+var stateMagic = [4]byte{'C', '8', 'S', 'T'}
+
+// ErrStateROM : The save state was made with a different ROM
+var ErrStateROM = errors.New("save state is for a different ROM")
+
+// machineState : Everything needed to resume a Machine, gob encoded and compressed by SaveState
+type machineState struct {
+	ROMHash    string
+	Platform   string
+	VIPHires   string
+	Quirks     Quirks
+	WrapX      string
+	WrapY      string
+	StackDepth int
+
+	PC, I, Opcode, SP      uint16
+	V                      [16]uint8
+	Memory                 []uint8
+	Screen                 [2][64][16]uint8
+	Width, Height          uint8
+	Hires                  bool
+	Planes                 uint8
+	RPL                    [16]uint8
+	Pattern                [16]uint8
+	Pitch                  uint8
+	DelayTimer, SoundTimer uint8
+	Stack                  []uint16
+	VBlankWait             bool
+	Halt                   HaltReason
+	Fault                  *Fault
+	Cycles                 uint64
+	Frames                 uint64
+	RNG                    uint64
+}
+
+// captureState : Snapshot of the machine
+func (vm *Machine) captureState() machineState {
+	return machineState{
+		ROMHash:    ROMHash(vm.rom),
+		Platform:   vm.platform,
+		VIPHires:   vm.vipHires,
+		Quirks:     vm.quirks,
+		WrapX:      vm.wrapX,
+		WrapY:      vm.wrapY,
+		StackDepth: vm.stackDepth,
+		PC:         vm.pc,
+		I:          vm.i,
+		Opcode:     vm.opcode,
+		SP:         vm.sp,
+		V:          vm.v,
+		Memory:     vm.memory[:vm.memSize],
+		Screen:     vm.screen,
+		Width:      vm.width,
+		Height:     vm.height,
+		Hires:      vm.hires,
+		Planes:     vm.planes,
+		RPL:        vm.rpl,
+		Pattern:    vm.pattern,
+		Pitch:      vm.pitch,
+		DelayTimer: vm.delayTimer,
+		SoundTimer: vm.soundTimer,
+		Stack:      vm.stack,
+		VBlankWait: vm.vblankWait,
+		Halt:       vm.halt,
+		Fault:      vm.fault,
+		Cycles:     vm.cycles,
+		Frames:     vm.frames,
+		RNG:        vm.rng,
+	}
+}
+
+// restoreState : Set the machine to a snapshot, which must be for the same platform
+func (vm *Machine) restoreState(state *machineState) {
+	vm.vipHires = state.VIPHires
+	vm.quirks = state.Quirks
+	vm.wrapX = state.WrapX
+	vm.wrapY = state.WrapY
+	vm.stackDepth = state.StackDepth
+	vm.pc = state.PC
+	vm.i = state.I
+	vm.opcode = state.Opcode
+	vm.sp = state.SP
+	vm.v = state.V
+	copy(vm.memory[:vm.memSize], state.Memory)
+	vm.screen = state.Screen
+	vm.width = state.Width
+	vm.height = state.Height
+	vm.hires = state.Hires
+	vm.planes = state.Planes
+	vm.rpl = state.RPL
+	vm.pattern = state.Pattern
+	vm.pitch = state.Pitch
+	vm.delayTimer = state.DelayTimer
+	vm.soundTimer = state.SoundTimer
+	vm.stack = append([]uint16(nil), state.Stack...)
+	vm.vblankWait = state.VBlankWait
+	vm.halt = state.Halt
+	vm.fault = state.Fault
+	vm.cycles = state.Cycles
+	vm.frames = state.Frames
+	vm.rng = state.RNG
+	vm.drawflag = false
+	vm.spin = spinLoop{}
+	vm.frameCycle = 0
+	vm.dirty = true
+}
+
+// SaveState : Write a snapshot of the machine, including the ROM hash and quirks, to w
+func (vm *Machine) SaveState(w io.Writer) error {
+	state := vm.captureState()
+
+	var header [6]byte
+	copy(header[:], stateMagic[:])
+	binary.BigEndian.PutUint16(header[4:], StateVersion)
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(w)
+	if err := gob.NewEncoder(zw).Encode(&state); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// LoadState : Restore a snapshot written by SaveState, the machine must have the same ROM and platform loaded
+// The quirks and wrapping stored in the snapshot replace those of the machine's Config.
+func (vm *Machine) LoadState(r io.Reader) error {
+	br := bufio.NewReader(r)
+	var header [6]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return fmt.Errorf("bad save state: %v", err)
+	}
+	if [4]byte{header[0], header[1], header[2], header[3]} != stateMagic {
+		return errors.New("bad save state: not a save state file")
+	}
+	version := binary.BigEndian.Uint16(header[4:])
+	if version < 1 || version > StateVersion {
+		return fmt.Errorf("unsupported save state version: %d, expected %d", version, StateVersion)
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return fmt.Errorf("bad save state: %v", err)
+	}
+	var state machineState
+	if err := gob.NewDecoder(zr).Decode(&state); err != nil {
+		return fmt.Errorf("bad save state: %v", err)
+	}
+
+	if state.ROMHash != ROMHash(vm.rom) {
+		return ErrStateROM
+	}
+	if state.Platform != vm.platform {
+		return fmt.Errorf("save state is for platform %s, machine is %s", state.Platform, vm.platform)
+	}
+	if len(state.Memory) != vm.memSize {
+		return fmt.Errorf("bad save state: %d bytes of memory, expected %d", len(state.Memory), vm.memSize)
+	}
+	if int(state.SP) > len(state.Stack) || state.Width > 128 || state.Height > 64 {
+		return errors.New("bad save state: registers out of range")
+	}
+
+	if version < 2 {
+		state.Frames = 0
+		state.RNG = uint64(vm.config.Seed)
+	}
+
+	vm.Reset()
+	vm.restoreState(&state)
+	return nil
+}
@@ -0,0 +1,153 @@
+package chip8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// stateROM : Counts V0 up in a subroutine, drawing a sprite each time
+var stateROM = []byte{
+	0xA2, 0x0A, // LD I, 0x20A
+	0x22, 0x06, // CALL 0x206
+	0x12, 0x02, // JP 0x202
+	0x70, 0x01, // ADD V0, 1
+	0xD1, 0x21, // DRW V1, V2, 1
+	0x00, 0xEE, // RET
+}
+
+func TestSaveLoadState(t *testing.T) {
+	config := Config{Platform: PlatformSCHIP, Quirks: QuirksPresets["schip"], WrapX: "clip"}
+	vm := New(config)
+	if err := vm.LoadROM(stateROM); err != nil {
+		t.Fatal(err)
+	}
+	for c := 0; c < 10; c++ {
+		vm.Step()
+	}
+	vm.SetDelayTimer(42)
+	vm.WriteMemory(0x300, 0xAB)
+
+	var buf bytes.Buffer
+	if err := vm.SaveState(&buf); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+	want := *vm
+	wantStack := vm.Stack()
+
+	for c := 0; c < 7; c++ {
+		vm.Step()
+	}
+	vm.WriteMemory(0x300, 0)
+
+	// load into a machine with different quirks, they should be restored from the state
+	loaded := New(Config{Platform: PlatformSCHIP})
+	if err := loaded.LoadROM(stateROM); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.LoadState(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if loaded.pc != want.pc || loaded.i != want.i || loaded.sp != want.sp || loaded.v != want.v {
+		t.Errorf("Registers incorrect, got PC: 0x%x I: 0x%x SP: %d V: %v, want PC: 0x%x I: 0x%x SP: %d V: %v",
+			loaded.pc, loaded.i, loaded.sp, loaded.v, want.pc, want.i, want.sp, want.v)
+	}
+	if loaded.memory != want.memory || loaded.screen != want.screen {
+		t.Errorf("Memory or screen incorrect")
+	}
+	if loaded.delayTimer != 42 || loaded.cycles != want.cycles {
+		t.Errorf("Timers incorrect, got DT: %d cycles: %d, want DT: 42 cycles: %d", loaded.delayTimer, loaded.cycles, want.cycles)
+	}
+	if loaded.quirks != want.quirks || loaded.wrapX != "clip" {
+		t.Errorf("Quirks incorrect, got: %+v wrapX: %s, want: %+v wrapX: clip", loaded.quirks, loaded.wrapX, want.quirks)
+	}
+	if fmt.Sprint(loaded.Stack()) != fmt.Sprint(wantStack) {
+		t.Errorf("Stack incorrect, got: %v, want: %v", loaded.Stack(), wantStack)
+	}
+
+	// the restored machine runs the same as the original
+	if err := vm.LoadState(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	for c := 0; c < 25; c++ {
+		vm.Step()
+		loaded.Step()
+	}
+	if vm.pc != loaded.pc || vm.v != loaded.v || vm.screen != loaded.screen {
+		t.Errorf("Machines diverged after loading, got PC: 0x%x V0: %d, want PC: 0x%x V0: %d",
+			loaded.pc, loaded.v[0], vm.pc, vm.v[0])
+	}
+}
+
+func TestLoadState_wrongROM(t *testing.T) {
+	vm := New(Config{})
+	vm.LoadROM(stateROM)
+	var buf bytes.Buffer
+	if err := vm.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	other := New(Config{})
+	other.LoadROM([]byte{0x12, 0x00})
+	if err := other.LoadState(&buf); !errors.Is(err, ErrStateROM) {
+		t.Errorf("Expected ErrStateROM, got: %v", err)
+	}
+}
+
+func TestLoadState_wrongPlatform(t *testing.T) {
+	vm := New(Config{Platform: PlatformXOChip})
+	vm.LoadROM(stateROM)
+	var buf bytes.Buffer
+	if err := vm.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	other := New(Config{Platform: PlatformChip8})
+	other.LoadROM(stateROM)
+	if err := other.LoadState(&buf); err == nil {
+		t.Errorf("Expected an error loading an XO-CHIP state on CHIP-8")
+	}
+}
+
+func TestLoadState_version(t *testing.T) {
+	vm := New(Config{})
+	vm.LoadROM(stateROM)
+	var buf bytes.Buffer
+	if err := vm.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	state := buf.Bytes()
+	binary.BigEndian.PutUint16(state[4:], StateVersion+1)
+	if err := vm.LoadState(bytes.NewReader(state)); err == nil {
+		t.Errorf("Expected an error for an unsupported version")
+	}
+	if err := vm.LoadState(bytes.NewReader([]byte("not a state"))); err == nil {
+		t.Errorf("Expected an error for a file without the header")
+	}
+}
+
+func TestLoadState_version1(t *testing.T) {
+	// version 1 states count frames from 0 and restart the random numbers from the seed
+	vm := New(Config{Seed: 7})
+	vm.LoadROM(stateROM)
+	for frame := 0; frame < 3; frame++ {
+		vm.RunFrame()
+	}
+	vm.random()
+	var buf bytes.Buffer
+	if err := vm.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	state := buf.Bytes()
+	binary.BigEndian.PutUint16(state[4:], 1)
+	if err := vm.LoadState(bytes.NewReader(state)); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := New(Config{Seed: 7})
+	if vm.Frames() != 0 || vm.random() != fresh.random() {
+		t.Errorf("Expected version 1 defaults, got: %d frames", vm.Frames())
+	}
+}
@@ -0,0 +1,140 @@
+package chip8
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Symbols : Labels and source lines of an assembled program, as written to a .sym file
+//
+// The file has one entry per line, with hex addresses:
+//
+//	label 0x208 draw_player
+//	line 0x200 12 game.8o
+//
+// where line gives the source file and line an instruction was assembled from. The file name
+// comes last so it may contain spaces, and relative names are relative to the .sym file.
+// Blank lines and lines starting with # are ignored.
+type Symbols struct {
+	Labels []Label      // sorted by address
+	Lines  []SourceLine // sorted by address
+}
+
+// Label : Named address
+type Label struct {
+	Name string
+	Addr uint16
+}
+
+// SourceLine : Source line an instruction at Addr was assembled from
+type SourceLine struct {
+	Addr uint16
+	File string
+	Line int
+}
+
+// ReadSymbols : Parse a .sym file
+func ReadSymbols(r io.Reader) (*Symbols, error) {
+	symbols := &Symbols{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.SplitN(text, " ", 4)
+		var addr uint64
+		var err error
+		if len(fields) >= 3 {
+			addr, err = strconv.ParseUint(strings.TrimPrefix(fields[1], "0x"), 16, 16)
+		}
+		switch {
+		case len(fields) < 3 || err != nil:
+		case fields[0] == "label" && len(fields) == 3:
+			symbols.Labels = append(symbols.Labels, Label{Name: fields[2], Addr: uint16(addr)})
+			continue
+		case fields[0] == "line" && len(fields) == 4:
+			line, err := strconv.Atoi(fields[2])
+			if err == nil {
+				symbols.Lines = append(symbols.Lines, SourceLine{Addr: uint16(addr), File: fields[3], Line: line})
+				continue
+			}
+		}
+		return nil, fmt.Errorf("bad symbol on line %d: %s", n, text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	symbols.sort()
+	return symbols, nil
+}
+
+func (symbols *Symbols) sort() {
+	sort.SliceStable(symbols.Labels, func(i, j int) bool { return symbols.Labels[i].Addr < symbols.Labels[j].Addr })
+	sort.SliceStable(symbols.Lines, func(i, j int) bool { return symbols.Lines[i].Addr < symbols.Lines[j].Addr })
+}
+
+// WriteTo : Write the symbols in the .sym file format
+func (symbols *Symbols) WriteTo(w io.Writer) (int64, error) {
+	symbols.sort()
+	bw := bufio.NewWriter(w)
+	var n int64
+	for _, label := range symbols.Labels {
+		c, _ := fmt.Fprintf(bw, "label 0x%03X %s\n", label.Addr, label.Name)
+		n += int64(c)
+	}
+	for _, line := range symbols.Lines {
+		c, _ := fmt.Fprintf(bw, "line 0x%03X %d %s\n", line.Addr, line.Line, line.File)
+		n += int64(c)
+	}
+	return n, bw.Flush()
+}
+
+// Label : Nearest label at or before addr and the offset from it, false if there is none
+func (symbols *Symbols) Label(addr uint16) (Label, uint16, bool) {
+	i := sort.Search(len(symbols.Labels), func(i int) bool { return symbols.Labels[i].Addr > addr })
+	if i == 0 {
+		return Label{}, 0, false
+	}
+	label := symbols.Labels[i-1]
+	return label, addr - label.Addr, true
+}
+
+// LabelAddr : Address of a label, false if there is no label with the name
+func (symbols *Symbols) LabelAddr(name string) (uint16, bool) {
+	for _, label := range symbols.Labels {
+		if label.Name == name {
+			return label.Addr, true
+		}
+	}
+	return 0, false
+}
+
+// Line : Source line of the instruction at addr, false if it has none
+func (symbols *Symbols) Line(addr uint16) (SourceLine, bool) {
+	i := sort.Search(len(symbols.Lines), func(i int) bool { return symbols.Lines[i].Addr >= addr })
+	if i < len(symbols.Lines) && symbols.Lines[i].Addr == addr {
+		return symbols.Lines[i], true
+	}
+	return SourceLine{}, false
+}
+
+// LineAddr : Address of the first instruction assembled from line of file, or from the next
+// line with an instruction after it. Returns that line too, false if there is none.
+func (symbols *Symbols) LineAddr(file string, line int) (SourceLine, bool) {
+	var best SourceLine
+	found := false
+	for _, l := range symbols.Lines {
+		if l.File != file || l.Line < line {
+			continue
+		}
+		if !found || l.Line < best.Line || l.Line == best.Line && l.Addr < best.Addr {
+			best, found = l, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,57 @@
+package chip8
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSymbols = `# game symbols
+label 0x208 draw
+label 0x200 main
+line 0x200 3 game.8o
+line 0x202 4 game.8o
+line 0x208 10 my game/draw.8o
+`
+
+func TestSymbols(t *testing.T) {
+	symbols, err := ReadSymbols(strings.NewReader(testSymbols))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label, offset, ok := symbols.Label(0x20A); !ok || label.Name != "draw" || offset != 2 {
+		t.Errorf("Expected draw+2, got: %v+%d", label, offset)
+	}
+	if _, _, ok := symbols.Label(0x100); ok {
+		t.Errorf("Expected no label before main")
+	}
+	if addr, ok := symbols.LabelAddr("main"); !ok || addr != 0x200 {
+		t.Errorf("Expected main at 0x200, got: 0x%x", addr)
+	}
+	if line, ok := symbols.Line(0x208); !ok || line.File != "my game/draw.8o" || line.Line != 10 {
+		t.Errorf("Expected draw.8o:10, got: %v", line)
+	}
+	if _, ok := symbols.Line(0x204); ok {
+		t.Errorf("Expected no line for 0x204")
+	}
+	if line, ok := symbols.LineAddr("game.8o", 1); !ok || line.Addr != 0x200 || line.Line != 3 {
+		t.Errorf("Expected line 1 to move to line 3, got: %v", line)
+	}
+	if _, ok := symbols.LineAddr("game.8o", 5); ok {
+		t.Errorf("Expected no address after the last line")
+	}
+
+	var out strings.Builder
+	if _, err := symbols.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	again, err := ReadSymbols(strings.NewReader(out.String()))
+	if err != nil || len(again.Labels) != 2 || len(again.Lines) != 3 || again.Labels[0].Name != "main" {
+		t.Errorf("Expected round trip incorrect, got: %v %v", again, err)
+	}
+
+	for _, bad := range []string{"label 0x20G x", "line 0x200 x game.8o", "symbol 0x200 x"} {
+		if _, err := ReadSymbols(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
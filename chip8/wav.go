@@ -0,0 +1,109 @@
+package chip8
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+// WAVRecorder : Audio that records the sound timer's tone, or XO-CHIP audio patterns, to a 16-bit mono WAV file
+// Each timer tick writes 1/timerSpeed seconds of samples from the tone, so the recording depends
+// only on the sound timer each frame: the same run always records the same bytes, however fast
+// it runs.
+type WAVRecorder struct {
+	w          io.WriteSeeker
+	buf        *bufio.Writer
+	tone       Tone
+	timerSpeed int
+	ticks      int64
+	written    int64 // samples written
+	samples    []int16
+	err        error // first write error, returned by Close
+}
+
+// NewWAVRecorder : Start a WAV recording of tone, at timerSpeed ticks per second (default: 60)
+// The header's sizes are filled in by Close.
+func NewWAVRecorder(w io.WriteSeeker, tone Tone, timerSpeed int) (*WAVRecorder, error) {
+	if timerSpeed == 0 {
+		timerSpeed = 60
+	}
+	recorder := &WAVRecorder{w: w, buf: bufio.NewWriter(w), tone: tone, timerSpeed: timerSpeed}
+	if err := recorder.header(); err != nil {
+		return nil, err
+	}
+	return recorder, nil
+}
+
+// header : Write the RIFF header for the samples written so far
+func (recorder *WAVRecorder) header() error {
+	rate := uint32(recorder.tone.Rate())
+	size := uint32(recorder.written * 2)
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'}, 36 + size, [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, uint32(16),
+		uint16(1),  // PCM
+		uint16(1),  // mono
+		rate,       // samples per second
+		rate * 2,   // bytes per second
+		uint16(2),  // bytes per sample
+		uint16(16), // bits per sample
+		[4]byte{'d', 'a', 't', 'a'}, size,
+	}
+	for _, field := range header {
+		if err := binary.Write(recorder.buf, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SoundTick : Record a tick of the tone, or of silence
+func (recorder *WAVRecorder) SoundTick(playing bool) {
+	recorder.record(func(samples []int16) {
+		recorder.tone.Samples(samples, playing)
+	})
+}
+
+// PatternTick : Record a tick of an XO-CHIP audio pattern, or of silence
+func (recorder *WAVRecorder) PatternTick(playing bool, pattern [16]uint8, rate float64) {
+	recorder.record(func(samples []int16) {
+		recorder.tone.PatternSamples(samples, playing, pattern, rate)
+	})
+}
+
+// record : Write a tick of samples made by fill
+func (recorder *WAVRecorder) record(fill func(samples []int16)) {
+	recorder.ticks++
+	n := int(recorder.ticks*int64(recorder.tone.Rate())/int64(recorder.timerSpeed) - recorder.written)
+	if cap(recorder.samples) < n {
+		recorder.samples = make([]int16, n)
+	}
+	samples := recorder.samples[:n]
+	fill(samples)
+	recorder.written += int64(n)
+	if recorder.err == nil {
+		recorder.err = binary.Write(recorder.buf, binary.LittleEndian, samples)
+	}
+}
+
+// Close : Finish the recording by filling in the header, returns the first error writing it
+// The underlying writer is left open.
+func (recorder *WAVRecorder) Close() error {
+	if recorder.err != nil {
+		return recorder.err
+	}
+	if err := recorder.buf.Flush(); err != nil {
+		return err
+	}
+	if _, err := recorder.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := recorder.header(); err != nil {
+		return err
+	}
+	if err := recorder.buf.Flush(); err != nil {
+		return err
+	}
+	_, err := recorder.w.Seek(0, io.SeekEnd)
+	return err
+}
@@ -0,0 +1,105 @@
+package chip8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordWAV : Record frames of a ROM to a WAV file, returning the file's contents
+func recordWAV(t *testing.T, config Config, rombytes []byte, frames int) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "out.wav")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	recorder, err := NewWAVRecorder(f, Tone{Volume: 0.5}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Audio = recorder
+	vm := New(config)
+	vm.LoadROM(rombytes)
+	for frame := 0; frame < frames; frame++ {
+		vm.RunFrame()
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWAVRecorder(t *testing.T) {
+	// LD V0, 3; LD ST, V0; LD V0, 60; LD DT, V0, then wait for the delay timer
+	rombytes := []byte{0x60, 0x03, 0xF0, 0x18, 0x60, 0x3C, 0xF0, 0x15, 0xF1, 0x07, 0x31, 0x00, 0x12, 0x08, 0x12, 0x0E}
+	data := recordWAV(t, Config{}, rombytes, 5)
+
+	const tick = 735 // samples per tick at 44100Hz and 60Hz
+	if len(data) != 44+5*tick*2 {
+		t.Fatalf("Expected WAV size incorrect, got: %d", len(data))
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:16]) != "WAVEfmt " || string(data[36:40]) != "data" {
+		t.Errorf("Expected WAV header incorrect, got: %q", data[:44])
+	}
+	if size := binary.LittleEndian.Uint32(data[40:44]); size != 5*tick*2 {
+		t.Errorf("Expected data size incorrect, got: %d", size)
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:28]); rate != 44100 {
+		t.Errorf("Expected sample rate incorrect, got: %d", rate)
+	}
+
+	samples := make([]int16, 5*tick)
+	binary.Read(bytes.NewReader(data[44:]), binary.LittleEndian, samples)
+	for tick, sounding := range []bool{true, true, true, false, false} {
+		loud := false
+		for _, sample := range samples[tick*735+300 : (tick+1)*735] {
+			loud = loud || sample != 0
+		}
+		if loud != sounding {
+			t.Errorf("Expected tick %d sounding to be %t", tick, sounding)
+		}
+	}
+
+	if again := recordWAV(t, Config{}, rombytes, 5); !bytes.Equal(again, data) {
+		t.Errorf("Expected identical recordings of the same run")
+	}
+}
+
+func TestWAVRecorder_pattern(t *testing.T) {
+	// LD I, pattern; AUDIO; LD V0, 8; LD ST, V0; LD V0, 112; PITCH V0; LD V0, 60; LD DT, V0,
+	// then wait for the delay timer
+	rombytes := []byte{0xA2, 0x18, 0xF0, 0x02, 0x60, 0x08, 0xF0, 0x18, 0x60, 0x70, 0xF0, 0x3A,
+		0x60, 0x3C, 0xF0, 0x15, 0xF1, 0x07, 0x31, 0x00, 0x12, 0x10, 0x12, 0x16,
+		0xF0, 0x0F, 0xCC, 0x33, 0xAA, 0x55, 0xFF, 0x00, 0x81, 0x42, 0x24, 0x18, 0x01, 0x02, 0x04, 0x08}
+	pattern := rombytes[24:]
+	data := recordWAV(t, Config{Platform: PlatformXOChip}, rombytes, 10)
+
+	samples := make([]int16, (len(data)-44)/2)
+	binary.Read(bytes.NewReader(data[44:]), binary.LittleEndian, samples)
+	const rate = 8000 // bits per second at pitch 112
+	for i := 300; i < 8*735; i++ {
+		// check the middle of each bit, away from rounding at the edges
+		position := float64(i) * rate / 44100
+		if frac := position - float64(int(position)); frac < 0.2 || frac > 0.8 {
+			continue
+		}
+		bit := int(position) % 128
+		high := pattern[bit/8]&(0x80>>(bit%8)) != 0
+		if (samples[i] > 0) != high || samples[i] == 0 {
+			t.Fatalf("Expected sample %d to play pattern bit %d (%t), got: %d", i, bit, high, samples[i])
+		}
+	}
+	for _, sample := range samples[9*735:] {
+		if sample != 0 {
+			t.Fatalf("Expected silence once the sound timer stopped, got: %d", sample)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// assembleFile : Assemble a source file into a ROM and a symbol file beside it, with .ch8 and
+// .sym extensions, returns the ROM's path
+func assembleFile(source string) (string, error) {
+	f, err := os.Open(source)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	rom, symbols, err := chip8.Assemble(filepath.Base(source), f)
+	if err != nil {
+		return "", err
+	}
+
+	base := strings.TrimSuffix(source, filepath.Ext(source))
+	if err := os.WriteFile(base+".ch8", rom, 0644); err != nil {
+		return "", err
+	}
+	sym, err := os.Create(base + ".sym")
+	if err != nil {
+		return "", err
+	}
+	if _, err := symbols.WriteTo(sym); err != nil {
+		sym.Close()
+		return "", err
+	}
+	return base + ".ch8", sym.Close()
+}
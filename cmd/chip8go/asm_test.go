@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssembleFile(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "halt.asm")
+	if err := os.WriteFile(source, []byte("start:\n    CLS\n    JP start\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rom, err := assembleFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(rom); !bytes.Equal(data, []byte{0x00, 0xE0, 0x12, 0x00}) {
+		t.Errorf("Expected ROM incorrect, got: % x", data)
+	}
+	if symbols, _ := os.ReadFile(filepath.Join(filepath.Dir(source), "halt.sym")); string(symbols) !=
+		"label 0x200 start\nline 0x200 2 halt.asm\nline 0x202 3 halt.asm\n" {
+		t.Errorf("Expected symbols incorrect, got:\n%s", symbols)
+	}
+}
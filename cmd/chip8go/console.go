@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+const consoleHelp = `Commands (addresses are hex, values are decimal unless prefixed with 0x):
+  c, continue          resume running
+  p, pause             pause (or press Enter while running, or the BREAK key in the window)
+  s, step [n]          run n instructions (default: 1)
+  n, next              step over a CALL
+  f, finish            run until the current subroutine returns
+  u, until <addr>      run until PC reaches addr
+  b, break <spec>      add a breakpoint: an address, op:Dxyn, or an expression such as V3 == 0x10 && DT == 0
+  b w:<addr>[-<end>]   add a watchpoint on writes to memory, r: for reads, rw: for both, i: for I in the range
+  d, delete <id>       remove a breakpoint
+  bl, breakpoints      list breakpoints
+  r, regs              show registers, stack, timers and disassembly
+  dis [addr] [n]       disassemble n instructions from addr (default: around PC)
+  x <addr> [n]         show n bytes of memory from addr (default: 16)
+  set <reg> <value>    set V0-VF, I, PC, DT or ST
+  w <addr> <byte>...   write bytes to memory
+  q, quit              quit chip8go
+An empty line repeats the last command while paused.`
+
+// console : Interactive debugger reading commands from the terminal
+type console struct {
+	vm       *chip8.Machine
+	debugger *chip8.Debugger
+	out      io.Writer
+	lines    chan string // commands read from the terminal, nil after the end of input
+	last     string      // command repeated by an empty line
+}
+
+// newConsole : Control the debugger of vm with commands read from in, and output written to out
+func newConsole(vm *chip8.Machine, debugger *chip8.Debugger, in io.Reader, out io.Writer) *console {
+	c := &console{vm: vm, debugger: debugger, out: out, lines: make(chan string)}
+	go func() {
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			c.lines <- scanner.Text()
+		}
+		close(c.lines)
+	}()
+	return c
+}
+
+// poll : Run the commands typed since the last call, returns false on quit
+func (c *console) poll() bool {
+	for {
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				c.lines = nil
+				return true
+			}
+			if !c.exec(line) {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
+// stopped : Report a break, or a fault with the break policy, and show where the machine stopped
+func (c *console) stopped(err error) {
+	if err != nil {
+		fmt.Fprintln(c.out, err)
+	}
+	c.show()
+	c.prompt()
+}
+
+func (c *console) prompt() {
+	if c.debugger.Paused() {
+		fmt.Fprint(c.out, "(chip8go) ")
+	}
+}
+
+// exec : Run a command, returns false on quit
+func (c *console) exec(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		if !c.debugger.Paused() {
+			c.debugger.Interrupt()
+			return true
+		}
+		fields = strings.Fields(c.last)
+		if len(fields) == 0 {
+			c.prompt()
+			return true
+		}
+	} else {
+		c.last = line
+	}
+
+	cmd, args := fields[0], fields[1:]
+	var err error
+	switch cmd {
+	case "h", "help":
+		fmt.Fprintln(c.out, consoleHelp)
+	case "q", "quit":
+		return false
+	case "c", "continue":
+		c.debugger.Continue()
+		return true
+	case "p", "pause":
+		c.debugger.Interrupt()
+		return true
+	case "s", "step":
+		n := 1
+		if len(args) > 0 {
+			n, err = strconv.Atoi(args[0])
+		}
+		for i := 0; i < n && err == nil; i++ {
+			err = c.step(c.debugger.Step())
+		}
+		if err == nil {
+			c.show()
+		}
+	case "n", "next":
+		err = c.step(c.debugger.StepOver())
+		if err == nil && c.debugger.Paused() {
+			c.show()
+		}
+		if !c.debugger.Paused() {
+			return true
+		}
+	case "f", "finish":
+		c.debugger.StepOut()
+		return true
+	case "u", "until":
+		var addr uint16
+		if addr, err = c.addressArg(args, 0); err == nil {
+			c.debugger.RunTo(addr)
+			return true
+		}
+	case "b", "break":
+		var bp chip8.Breakpoint
+		if bp, err = c.debugger.AddBreakpoint(strings.Join(args, " ")); err == nil {
+			fmt.Fprintf(c.out, "Breakpoint %d: %s\n", bp.ID, bp.Spec)
+		}
+	case "d", "delete":
+		var id int
+		if len(args) == 0 {
+			err = errors.New("missing breakpoint ID")
+		} else if id, err = strconv.Atoi(args[0]); err == nil && !c.debugger.RemoveBreakpoint(id) {
+			err = fmt.Errorf("no breakpoint %d", id)
+		}
+	case "bl", "breakpoints":
+		for _, bp := range c.debugger.Breakpoints() {
+			fmt.Fprintf(c.out, "%d: %s (%d hits)\n", bp.ID, bp.Spec, bp.Hits)
+		}
+	case "r", "regs":
+		c.show()
+	case "dis":
+		addr, n := c.vm.PC(), 10
+		if len(args) > 0 {
+			addr, err = c.addressArg(args, 0)
+		}
+		if len(args) > 1 && err == nil {
+			n, err = strconv.Atoi(args[1])
+		}
+		if err == nil {
+			c.disassemble(addr, n)
+		}
+	case "x":
+		var addr uint16
+		n := 16
+		addr, err = c.addressArg(args, 0)
+		if len(args) > 1 && err == nil {
+			n, err = strconv.Atoi(args[1])
+		}
+		if err == nil {
+			c.dump(addr, n)
+		}
+	case "set":
+		err = c.set(args)
+	case "w":
+		var addr uint16
+		addr, err = c.addressArg(args, 0)
+		for i, arg := range args[1:] {
+			if err != nil {
+				break
+			}
+			var value uint64
+			if value, err = strconv.ParseUint(arg, 0, 8); err == nil {
+				c.vm.WriteMemory(addr+uint16(i), uint8(value))
+			}
+		}
+	default:
+		err = fmt.Errorf("unknown command: %s, type help for a list", cmd)
+	}
+	if err != nil {
+		fmt.Fprintln(c.out, err)
+	}
+	c.prompt()
+	return true
+}
+
+// step : Report a fault or halt after a step, as an error that stops further steps
+func (c *console) step(running bool, err error) error {
+	if err != nil {
+		return err
+	}
+	if !running {
+		return fmt.Errorf("program halted (%s) at PC: 0x%x", c.vm.Halted(), c.vm.PC())
+	}
+	return nil
+}
+
+// addressArg : Hex address in args[i], with or without the 0x prefix
+func (c *console) addressArg(args []string, i int) (uint16, error) {
+	if i >= len(args) {
+		return 0, errors.New("missing address")
+	}
+	return parseAddress(c.vm, args[i])
+}
+
+// parseAddress : Hex address within the memory of vm, with or without the 0x prefix
+func parseAddress(vm *chip8.Machine, s string) (uint16, error) {
+	addr, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 16)
+	if err != nil || int(addr) >= vm.MemorySize() {
+		return 0, fmt.Errorf("bad address: %s", s)
+	}
+	return uint16(addr), nil
+}
+
+func (c *console) set(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: set <reg> <value>")
+	}
+	return setRegister(c.vm, args[0], args[1])
+}
+
+// setRegister : Set V0-VF, I, PC, DT or ST, with addresses in hex and other values decimal
+// unless prefixed with 0x
+func setRegister(vm *chip8.Machine, register, value string) error {
+	name := strings.ToUpper(register)
+	switch name {
+	case "PC", "I":
+		addr, err := parseAddress(vm, value)
+		if err != nil {
+			return err
+		}
+		if name == "PC" {
+			vm.SetPC(addr)
+		} else {
+			vm.SetI(addr)
+		}
+		return nil
+	}
+	v, err := strconv.ParseUint(value, 0, 8)
+	if err != nil {
+		return fmt.Errorf("bad value: %s", value)
+	}
+	switch {
+	case name == "DT":
+		vm.SetDelayTimer(uint8(v))
+	case name == "ST":
+		vm.SetSoundTimer(uint8(v))
+	case len(name) == 2 && name[0] == 'V' && strings.ContainsRune("0123456789ABCDEF", rune(name[1])):
+		x, _ := strconv.ParseUint(name[1:], 16, 8)
+		vm.SetV(uint8(x), uint8(v))
+	default:
+		return fmt.Errorf("unknown register: %s", register)
+	}
+	return nil
+}
+
+// show : Print the registers, stack, timers and the disassembly around PC
+func (c *console) show() {
+	vm := c.vm
+	fmt.Fprintf(c.out, "PC: 0x%03x  I: 0x%03x  SP: %d  DT: %d  ST: %d  cycles: %d\n",
+		vm.PC(), vm.I(), vm.SP(), vm.DelayTimer(), vm.SoundTimer(), vm.Cycles())
+	for x := uint8(0); x < 16; x++ {
+		fmt.Fprintf(c.out, "V%X: %02x ", x, vm.V(x))
+		if x == 7 || x == 15 {
+			fmt.Fprintln(c.out)
+		}
+	}
+	fmt.Fprint(c.out, "Stack:")
+	for _, addr := range vm.Stack() {
+		fmt.Fprintf(c.out, " 0x%03x", addr)
+	}
+	fmt.Fprintln(c.out)
+	start := int(vm.PC()) - 8 // instructions before PC, assuming they are 2 bytes
+	if start < 0 {
+		start = int(vm.PC()) % 2
+	}
+	c.disassemble(uint16(start), 10)
+}
+
+// disassemble : Print n instructions from addr, marking PC
+func (c *console) disassemble(addr uint16, n int) {
+	for i := 0; i < n && int(addr) < c.vm.MemorySize(); i++ {
+		text, size := c.vm.DisassembleAt(addr)
+		marker := "  "
+		if addr == c.vm.PC() {
+			marker = "> "
+		}
+		var code strings.Builder
+		for b := 0; b < size; b++ {
+			fmt.Fprintf(&code, "%02X", c.vm.ReadMemory(addr+uint16(b)))
+		}
+		fmt.Fprintf(c.out, "%s0x%03x  %-8s  %s\n", marker, addr, code.String(), text)
+		addr += uint16(size)
+	}
+}
+
+// dump : Print n bytes of memory from addr, 16 per line
+func (c *console) dump(addr uint16, n int) {
+	for i := 0; i < n && int(addr)+i < c.vm.MemorySize(); i++ {
+		if i%16 == 0 {
+			if i > 0 {
+				fmt.Fprintln(c.out)
+			}
+			fmt.Fprintf(c.out, "0x%03x:", int(addr)+i)
+		}
+		fmt.Fprintf(c.out, " %02x", c.vm.ReadMemory(addr+uint16(i)))
+	}
+	fmt.Fprintln(c.out)
+}
+
+// debugSession : Debugger attached to the machine, controlled from the console, a GDB client and/or an IDE
+type debugSession struct {
+	debugger *chip8.Debugger
+	console  *console         // nil without -debugger
+	gdb      *chip8.GDBServer // nil without -gdb
+	dap      *dapServer       // nil without the dap command or -dap
+}
+
+// newDebugSession : Attach a debugger to vm, with the console started paused if enabled, a
+// GDB server listening on gdbAddr unless it is empty, and the DAP server if not nil
+func newDebugSession(vm *chip8.Machine, withConsole bool, gdbAddr string, dap *dapServer) (*debugSession, error) {
+	session := &debugSession{debugger: chip8.NewDebugger(vm), dap: dap}
+	if dap != nil {
+		dap.attach(vm, session.debugger)
+	}
+	if gdbAddr != "" {
+		gdb, err := chip8.ListenGDB(session.debugger, gdbAddr)
+		if err != nil {
+			return nil, err
+		}
+		session.gdb = gdb
+		log.Printf("GDB server listening on %s", gdb.Addr())
+	}
+	if withConsole {
+		session.console = newConsole(vm, session.debugger, os.Stdin, os.Stdout)
+		session.debugger.Interrupt() // start paused, to set breakpoints
+	}
+	return session, nil
+}
+
+// poll : Handle console commands, GDB packets and DAP requests, returns false on quit
+func (session *debugSession) poll() bool {
+	if session.gdb != nil {
+		session.gdb.Poll()
+	}
+	if session.dap != nil && !session.dap.poll() {
+		return false
+	}
+	return session.console == nil || session.console.poll()
+}
+
+// stopped : Report a break in the console, GDB and DAP clients are told by the next poll
+func (session *debugSession) stopped(err error) {
+	if session.console != nil {
+		session.console.stopped(err)
+	}
+}
+
+// close : Tell GDB and DAP clients how the machine stopped, then stop listening
+func (session *debugSession) close() {
+	if session.gdb != nil {
+		session.gdb.Poll()
+		session.gdb.Close()
+	}
+	if session.dap != nil {
+		session.dap.close()
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+func TestConsole(t *testing.T) {
+	rombytes := readROM("../../roms/programs/IBM Logo.ch8")
+	vm, err := newMachine(chip8.Config{}, rombytes, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	c := newConsole(vm, chip8.NewDebugger(vm), strings.NewReader(""), &out)
+	c.debugger.Interrupt()
+	if _, err := vm.Step(); err == nil || !c.debugger.Paused() {
+		t.Fatalf("Expected interrupt, got: %v", err)
+	}
+
+	for _, line := range []string{"s 2", "", "set V3 0x2a", "set I 300", "w 300 1 0x02", "x 300 2", "b 0x228", "bl", "dis 200 1"} {
+		if !c.exec(line) {
+			t.Fatalf("Command %q quit", line)
+		}
+	}
+	if vm.PC() != 0x208 || vm.V(3) != 0x2a || vm.I() != 0x300 || vm.ReadMemory(0x301) != 2 {
+		t.Errorf("Expected machine incorrect, got PC: 0x%x V3: 0x%x I: 0x%x", vm.PC(), vm.V(3), vm.I())
+	}
+	for _, want := range []string{"> 0x208", "0x300: 01 02", "1: 0x228 (0 hits)", "0x200  00E0      CLS"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output %q missing, got:\n%s", want, out.String())
+		}
+	}
+
+	c.exec("c")
+	for f := 0; f < 10 && !c.debugger.Paused(); f++ {
+		vm.RunFrame()
+	}
+	if vm.PC() != 0x228 {
+		t.Errorf("Expected breakpoint at 0x228, got PC: 0x%x", vm.PC())
+	}
+	if c.exec("q") {
+		t.Errorf("Expected quit")
+	}
+}
@@ -0,0 +1,741 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// dapServer : Debug Adapter Protocol server for IDEs, on stdio or a TCP address
+// With the dap command the IDE launches the ROM, otherwise it attaches to the running one.
+// Messages are read on background goroutines and handled by poll on the main goroutine.
+type dapServer struct {
+	listener net.Listener  // nil on stdio
+	events   chan dapEvent // messages, connections and disconnections
+	conn     *dapConn      // connected client, nil when there is none
+	seq      int
+
+	program     string // ROM file, for the default symbols
+	launch      dapLaunchArguments
+	launched    bool // the client launched the ROM, so quits it on disconnect
+	configured  bool // configurationDone received, stops are reported from now on
+	entry       bool // the next stop reported is the launch stopping on entry
+	reported    bool // the current pause has been reported to the client
+	quit        bool
+	vm          *chip8.Machine
+	debugger    *chip8.Debugger
+	symbols     *chip8.Symbols
+	compiled    *chip8.Symbols   // symbols of a program compiled from source, used without a .sym file
+	symbolsDir  string           // directory relative source file names are in
+	breakpoints map[string][]int // debugger breakpoint IDs for each source file, "" for instruction breakpoints
+}
+
+// dapConn : Client connection, stdin and stdout or a TCP connection
+type dapConn struct {
+	r      io.Reader
+	w      io.Writer
+	closer io.Closer // nil on stdio
+}
+
+// dapEvent : Message, connection or disconnection from a client
+type dapEvent struct {
+	conn      *dapConn
+	message   *dapMessage
+	connected bool
+	closed    bool
+}
+
+// dapMessage : Request from the client
+type dapMessage struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type dapResponse struct {
+	Seq        int         `json:"seq"`
+	Type       string      `json:"type"`
+	RequestSeq int         `json:"request_seq"`
+	Success    bool        `json:"success"`
+	Command    string      `json:"command"`
+	Message    string      `json:"message,omitempty"`
+	Body       interface{} `json:"body,omitempty"`
+}
+
+type dapEventMessage struct {
+	Seq   int         `json:"seq"`
+	Type  string      `json:"type"`
+	Event string      `json:"event"`
+	Body  interface{} `json:"body,omitempty"`
+}
+
+// dapLaunchArguments : Arguments of launch and attach requests, in the IDE's launch configuration
+type dapLaunchArguments struct {
+	Program     string `json:"program"`     // ROM file to launch
+	Symbols     string `json:"symbols"`     // .sym file (default: the program with a .sym extension, if it exists)
+	StopOnEntry bool   `json:"stopOnEntry"` // pause before the first instruction
+}
+
+type dapSource struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+type dapBreakpoint struct {
+	ID                   int        `json:"id,omitempty"`
+	Verified             bool       `json:"verified"`
+	Message              string     `json:"message,omitempty"`
+	Source               *dapSource `json:"source,omitempty"`
+	Line                 int        `json:"line,omitempty"`
+	InstructionReference string     `json:"instructionReference,omitempty"`
+}
+
+type dapStackFrame struct {
+	ID                          int        `json:"id"`
+	Name                        string     `json:"name"`
+	Source                      *dapSource `json:"source,omitempty"`
+	Line                        int        `json:"line"`
+	Column                      int        `json:"column"`
+	InstructionPointerReference string     `json:"instructionPointerReference"`
+}
+
+type dapVariable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	VariablesReference int    `json:"variablesReference"`
+	MemoryReference    string `json:"memoryReference,omitempty"`
+}
+
+// Variable references of the scopes
+const (
+	dapRegisters = 1
+	dapTimers    = 2
+)
+
+// dapThread : ID of the machine's only thread
+const dapThread = 1
+
+func newDAPServer() *dapServer {
+	return &dapServer{events: make(chan dapEvent, 16), breakpoints: make(map[string][]int)}
+}
+
+// stdioDAP : Serve a single client on in and out, launched by the IDE
+func stdioDAP(in io.Reader, out io.Writer) *dapServer {
+	server := newDAPServer()
+	go server.read(&dapConn{r: in, w: out})
+	return server
+}
+
+// listenDAP : Serve clients on a TCP address, one at a time
+func listenDAP(addr string) (*dapServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	server := newDAPServer()
+	server.listener = listener
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.read(&dapConn{r: conn, w: conn, closer: conn})
+		}
+	}()
+	return server, nil
+}
+
+// read : Read messages from a client until it disconnects
+func (server *dapServer) read(conn *dapConn) {
+	server.events <- dapEvent{conn: conn, connected: true}
+	r := bufio.NewReader(conn.r)
+	for {
+		message := &dapMessage{}
+		if err := readDAPMessage(r, message); err != nil {
+			if err != io.EOF {
+				log.Printf("DAP: %v", err)
+			}
+			break
+		}
+		server.events <- dapEvent{conn: conn, message: message}
+	}
+	server.events <- dapEvent{conn: conn, closed: true}
+}
+
+// readDAPMessage : Read a message framed by a Content-Length header into v
+func readDAPMessage(r *bufio.Reader, v interface{}) error {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if length < 0 {
+				continue
+			}
+			break
+		}
+		if value := strings.TrimPrefix(line, "Content-Length:"); value != line {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return fmt.Errorf("bad header: %s", line)
+			}
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func (server *dapServer) write(message interface{}) {
+	if server.conn == nil {
+		return
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("DAP: %v", err)
+		return
+	}
+	fmt.Fprintf(server.conn.w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (server *dapServer) respond(request *dapMessage, body interface{}) {
+	server.seq++
+	server.write(dapResponse{Seq: server.seq, Type: "response", RequestSeq: request.Seq, Success: true, Command: request.Command, Body: body})
+}
+
+func (server *dapServer) fail(request *dapMessage, err error) {
+	server.seq++
+	server.write(dapResponse{Seq: server.seq, Type: "response", RequestSeq: request.Seq, Command: request.Command, Message: err.Error()})
+}
+
+func (server *dapServer) event(event string, body interface{}) {
+	server.seq++
+	server.write(dapEventMessage{Seq: server.seq, Type: "event", Event: event, Body: body})
+}
+
+// waitLaunch : Answer the client until it sends a launch request, returns the ROM file to launch
+func (server *dapServer) waitLaunch() (string, error) {
+	for event := range server.events {
+		switch {
+		case event.connected:
+			server.conn = event.conn
+		case event.closed:
+			return "", errors.New("debug client disconnected before launching")
+		case event.message.Command == "initialize":
+			server.initialize(event.message)
+		case event.message.Command == "launch":
+			if err := json.Unmarshal(event.message.Arguments, &server.launch); err != nil || server.launch.Program == "" {
+				server.fail(event.message, errors.New("missing program to launch"))
+				continue
+			}
+			server.launched = true
+			server.program = server.launch.Program
+			server.respond(event.message, nil)
+			return server.program, nil
+		case event.message.Command == "disconnect":
+			server.respond(event.message, nil)
+			return "", errors.New("debug client disconnected before launching")
+		default:
+			server.fail(event.message, errors.New("not launched"))
+		}
+	}
+	return "", errors.New("debug client disconnected before launching")
+}
+
+// attach : Debug vm, launched with waitLaunch or running when a client attaches
+func (server *dapServer) attach(vm *chip8.Machine, debugger *chip8.Debugger) {
+	server.vm, server.debugger = vm, debugger
+	if server.launched {
+		server.loadSymbols(server.launch.Symbols)
+		debugger.Interrupt() // wait for the client's breakpoints, until configurationDone
+		server.event("initialized", nil)
+	}
+}
+
+// loadSymbols : Read the .sym file for source breakpoints, by default next to the program,
+// or use the symbols of a program compiled from source
+func (server *dapServer) loadSymbols(path string) {
+	server.symbols, server.symbolsDir = nil, ""
+	if path == "" && server.compiled != nil {
+		server.symbols, server.symbolsDir = server.compiled, filepath.Dir(server.program)
+		return
+	}
+	if path == "" {
+		path = strings.TrimSuffix(server.program, filepath.Ext(server.program)) + ".sym"
+		if _, err := os.Stat(path); err != nil {
+			return
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	defer f.Close()
+	if server.symbols, err = chip8.ReadSymbols(f); err != nil {
+		log.Print(err)
+		return
+	}
+	server.symbolsDir = filepath.Dir(path)
+}
+
+// poll : Handle the requests received since the last call, and report a pause to the client,
+// returns false when the client quits a launched ROM
+func (server *dapServer) poll() bool {
+	for {
+		select {
+		case event := <-server.events:
+			server.handle(event)
+			continue
+		default:
+		}
+		break
+	}
+	if server.conn != nil && server.configured {
+		switch {
+		case !server.debugger.Paused():
+			server.reported = false
+		case !server.reported:
+			server.reported = true
+			server.event("stopped", server.stoppedBody())
+			server.entry = false
+		}
+	}
+	return !server.quit
+}
+
+// close : Tell the client the program ended, then stop listening
+func (server *dapServer) close() {
+	if server.conn != nil && !server.quit {
+		exitCode := 0
+		if server.vm.Halted() == chip8.HaltFault {
+			exitCode = 1
+		}
+		server.event("exited", map[string]int{"exitCode": exitCode})
+		server.event("terminated", nil)
+		if server.conn.closer != nil {
+			server.conn.closer.Close()
+		}
+	}
+	if server.listener != nil {
+		server.listener.Close()
+	}
+}
+
+func (server *dapServer) handle(event dapEvent) {
+	switch {
+	case event.connected:
+		server.conn = event.conn
+		server.configured = false
+	case event.conn != server.conn:
+		// messages from a client that has since been replaced
+	case event.closed:
+		server.detach()
+		server.conn = nil
+	default:
+		if err := server.request(event.message); err != nil {
+			server.fail(event.message, err)
+		}
+	}
+}
+
+// detach : Remove the client's breakpoints and resume the machine, or quit a launched ROM
+func (server *dapServer) detach() {
+	if server.launched {
+		server.quit = true
+		return
+	}
+	for key, ids := range server.breakpoints {
+		for _, id := range ids {
+			server.debugger.RemoveBreakpoint(id)
+		}
+		delete(server.breakpoints, key)
+	}
+	server.configured = false
+	if server.debugger.Paused() {
+		server.debugger.Continue()
+	}
+}
+
+func (server *dapServer) initialize(request *dapMessage) {
+	server.respond(request, map[string]bool{
+		"supportsConfigurationDoneRequest": true,
+		"supportsSetVariable":              true,
+		"supportsEvaluateForHovers":        true,
+		"supportsReadMemoryRequest":        true,
+		"supportsWriteMemoryRequest":       true,
+		"supportsInstructionBreakpoints":   true,
+		"supportsTerminateRequest":         true,
+	})
+}
+
+// request : Handle a request, responding unless it fails
+func (server *dapServer) request(request *dapMessage) error {
+	vm, debugger := server.vm, server.debugger
+	switch request.Command {
+	case "initialize":
+		server.initialize(request)
+	case "launch":
+		return fmt.Errorf("chip8go is already running %s, use an attach request", server.program)
+	case "attach":
+		var args dapLaunchArguments
+		json.Unmarshal(request.Arguments, &args)
+		server.loadSymbols(args.Symbols)
+		server.respond(request, nil)
+		server.event("initialized", nil)
+	case "configurationDone":
+		server.configured, server.reported = true, false
+		if server.launched && server.launch.StopOnEntry {
+			server.entry = true
+		} else if server.launched {
+			debugger.Continue()
+		}
+		server.respond(request, nil)
+	case "disconnect", "terminate":
+		server.respond(request, nil)
+		server.detach()
+	case "setBreakpoints":
+		return server.setBreakpoints(request)
+	case "setInstructionBreakpoints":
+		return server.setInstructionBreakpoints(request)
+	case "setExceptionBreakpoints":
+		server.respond(request, map[string][]dapBreakpoint{"breakpoints": {}})
+	case "threads":
+		server.respond(request, map[string]interface{}{
+			"threads": []map[string]interface{}{{"id": dapThread, "name": "CHIP-8"}},
+		})
+	case "stackTrace":
+		frames := server.stackTrace()
+		server.respond(request, map[string]interface{}{"stackFrames": frames, "totalFrames": len(frames)})
+	case "scopes":
+		server.respond(request, map[string]interface{}{
+			"scopes": []map[string]interface{}{
+				{"name": "Registers", "variablesReference": dapRegisters, "expensive": false},
+				{"name": "Timers", "variablesReference": dapTimers, "expensive": false},
+			},
+		})
+	case "variables":
+		var args struct {
+			VariablesReference int `json:"variablesReference"`
+		}
+		json.Unmarshal(request.Arguments, &args)
+		server.respond(request, map[string][]dapVariable{"variables": server.variables(args.VariablesReference)})
+	case "setVariable":
+		var args struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		}
+		json.Unmarshal(request.Arguments, &args)
+		if err := setRegister(vm, args.Name, args.Value); err != nil {
+			return err
+		}
+		value := args.Value
+		for _, v := range append(server.variables(dapRegisters), server.variables(dapTimers)...) {
+			if strings.EqualFold(v.Name, args.Name) {
+				value = v.Value
+			}
+		}
+		server.respond(request, map[string]string{"value": value})
+	case "evaluate":
+		var args struct {
+			Expression string `json:"expression"`
+		}
+		json.Unmarshal(request.Arguments, &args)
+		expr, err := chip8.ParseExpression(args.Expression)
+		if err != nil {
+			return err
+		}
+		value := expr(vm)
+		server.respond(request, map[string]interface{}{
+			"result":             fmt.Sprintf("%d (0x%X)", value, value),
+			"variablesReference": 0,
+		})
+	case "readMemory":
+		return server.readMemory(request)
+	case "writeMemory":
+		return server.writeMemory(request)
+	case "continue":
+		debugger.Continue()
+		server.respond(request, map[string]bool{"allThreadsContinued": true})
+	case "next":
+		debugger.StepOver()
+		server.reported = false
+		server.respond(request, nil)
+	case "stepIn":
+		debugger.Step()
+		server.reported = false
+		server.respond(request, nil)
+	case "stepOut":
+		debugger.StepOut()
+		server.respond(request, nil)
+	case "pause":
+		debugger.Interrupt()
+		server.respond(request, nil)
+	default:
+		return fmt.Errorf("unsupported request: %s", request.Command)
+	}
+	return nil
+}
+
+// stoppedBody : Stopped event for the break that paused the machine
+func (server *dapServer) stoppedBody() map[string]interface{} {
+	body := map[string]interface{}{"threadId": dapThread, "allThreadsStopped": true}
+	brk := server.debugger.Stopped()
+	switch {
+	case brk == nil:
+		body["reason"] = "exception"
+		body["description"] = "Paused on a fault"
+		return body
+	case brk.Access != nil:
+		body["reason"] = "data breakpoint"
+		body["hitBreakpointIds"] = []int{brk.Breakpoint}
+	case brk.Breakpoint != 0:
+		body["reason"] = "breakpoint"
+		body["hitBreakpointIds"] = []int{brk.Breakpoint}
+	case server.entry:
+		body["reason"] = "entry"
+	case brk.Reason == "interrupt":
+		body["reason"] = "pause"
+	default:
+		body["reason"] = "step"
+	}
+	body["description"] = brk.Error()
+	return body
+}
+
+// setBreakpoints : Replace the breakpoints of a source file, mapped to addresses by the symbols
+func (server *dapServer) setBreakpoints(request *dapMessage) error {
+	var args struct {
+		Source      dapSource `json:"source"`
+		Breakpoints []struct {
+			Line int `json:"line"`
+		} `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(request.Arguments, &args); err != nil {
+		return err
+	}
+	path := filepath.Clean(args.Source.Path)
+	for _, id := range server.breakpoints[path] {
+		server.debugger.RemoveBreakpoint(id)
+	}
+	server.breakpoints[path] = nil
+
+	file, known := server.symbolFile(path)
+	var result []dapBreakpoint
+	for _, requested := range args.Breakpoints {
+		bp := dapBreakpoint{Line: requested.Line, Source: &args.Source}
+		var line chip8.SourceLine
+		ok := false
+		if known {
+			line, ok = server.symbols.LineAddr(file, requested.Line)
+		}
+		if !ok {
+			bp.Message = "No code for this line in the symbols"
+			result = append(result, bp)
+			continue
+		}
+		added, err := server.debugger.AddBreakpoint(fmt.Sprintf("0x%x", line.Addr))
+		if err != nil {
+			return err
+		}
+		server.breakpoints[path] = append(server.breakpoints[path], added.ID)
+		bp.ID, bp.Verified, bp.Line = added.ID, true, line.Line
+		bp.InstructionReference = fmt.Sprintf("0x%03X", line.Addr)
+		result = append(result, bp)
+	}
+	server.respond(request, map[string][]dapBreakpoint{"breakpoints": result})
+	return nil
+}
+
+// setInstructionBreakpoints : Replace the breakpoints on addresses, e.g. from a disassembly view
+func (server *dapServer) setInstructionBreakpoints(request *dapMessage) error {
+	var args struct {
+		Breakpoints []struct {
+			InstructionReference string `json:"instructionReference"`
+			Offset               int    `json:"offset"`
+		} `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(request.Arguments, &args); err != nil {
+		return err
+	}
+	for _, id := range server.breakpoints[""] {
+		server.debugger.RemoveBreakpoint(id)
+	}
+	server.breakpoints[""] = nil
+
+	var result []dapBreakpoint
+	for _, requested := range args.Breakpoints {
+		addr, err := parseAddress(server.vm, requested.InstructionReference)
+		if err != nil {
+			result = append(result, dapBreakpoint{Message: err.Error()})
+			continue
+		}
+		addr += uint16(requested.Offset)
+		added, err := server.debugger.AddBreakpoint(fmt.Sprintf("0x%x", addr))
+		if err != nil {
+			return err
+		}
+		server.breakpoints[""] = append(server.breakpoints[""], added.ID)
+		result = append(result, dapBreakpoint{ID: added.ID, Verified: true, InstructionReference: fmt.Sprintf("0x%03X", addr)})
+	}
+	server.respond(request, map[string][]dapBreakpoint{"breakpoints": result})
+	return nil
+}
+
+// symbolFile : Source file name in the symbols for a path from the client
+func (server *dapServer) symbolFile(path string) (string, bool) {
+	if server.symbols == nil {
+		return "", false
+	}
+	for _, line := range server.symbols.Lines {
+		if server.sourcePath(line.File) == path {
+			return line.File, true
+		}
+	}
+	return "", false
+}
+
+// sourcePath : Path of a source file from the symbols, relative to the .sym file
+func (server *dapServer) sourcePath(file string) string {
+	if filepath.IsAbs(file) {
+		return filepath.Clean(file)
+	}
+	path, err := filepath.Abs(filepath.Join(server.symbolsDir, file))
+	if err != nil {
+		return filepath.Join(server.symbolsDir, file)
+	}
+	return path
+}
+
+// stackTrace : Frames for PC and each CALL on the stack, innermost first
+func (server *dapServer) stackTrace() []dapStackFrame {
+	addrs := []uint16{server.vm.PC()}
+	stack := server.vm.Stack()
+	for i := len(stack) - 1; i >= 0; i-- {
+		addrs = append(addrs, stack[i])
+	}
+	frames := make([]dapStackFrame, len(addrs))
+	for i, addr := range addrs {
+		frame := dapStackFrame{ID: i, Name: fmt.Sprintf("0x%03X", addr), InstructionPointerReference: fmt.Sprintf("0x%03X", addr)}
+		if server.symbols != nil {
+			if label, offset, ok := server.symbols.Label(addr); ok {
+				frame.Name = label.Name
+				if offset != 0 {
+					frame.Name = fmt.Sprintf("%s+%d", label.Name, offset)
+				}
+			}
+			if line, ok := server.symbols.Line(addr); ok {
+				path := server.sourcePath(line.File)
+				frame.Source = &dapSource{Name: filepath.Base(path), Path: path}
+				frame.Line, frame.Column = line.Line, 1
+			}
+		}
+		frames[i] = frame
+	}
+	return frames
+}
+
+// variables : Registers or timers, with addresses in hex and I as a memory reference
+func (server *dapServer) variables(ref int) []dapVariable {
+	vm := server.vm
+	switch ref {
+	case dapRegisters:
+		var variables []dapVariable
+		for x := uint8(0); x < 16; x++ {
+			variables = append(variables, dapVariable{Name: fmt.Sprintf("V%X", x), Value: fmt.Sprintf("0x%02X", vm.V(x))})
+		}
+		return append(variables,
+			dapVariable{Name: "I", Value: fmt.Sprintf("0x%03X", vm.I()), MemoryReference: fmt.Sprintf("0x%03X", vm.I())},
+			dapVariable{Name: "PC", Value: fmt.Sprintf("0x%03X", vm.PC()), MemoryReference: fmt.Sprintf("0x%03X", vm.PC())},
+			dapVariable{Name: "SP", Value: strconv.Itoa(int(vm.SP()))},
+		)
+	case dapTimers:
+		return []dapVariable{
+			{Name: "DT", Value: strconv.Itoa(int(vm.DelayTimer()))},
+			{Name: "ST", Value: strconv.Itoa(int(vm.SoundTimer()))},
+		}
+	}
+	return []dapVariable{}
+}
+
+// memoryRange : Start address and length of a read or write, clipped to memory
+func (server *dapServer) memoryRange(reference string, offset, count int) (int, int, error) {
+	addr, err := parseAddress(server.vm, reference)
+	if err != nil {
+		return 0, 0, err
+	}
+	start := int(addr) + offset
+	if start < 0 || start > server.vm.MemorySize() {
+		return 0, 0, fmt.Errorf("address out of range: 0x%x", start)
+	}
+	if start+count > server.vm.MemorySize() {
+		count = server.vm.MemorySize() - start
+	}
+	return start, count, nil
+}
+
+func (server *dapServer) readMemory(request *dapMessage) error {
+	var args struct {
+		MemoryReference string `json:"memoryReference"`
+		Offset          int    `json:"offset"`
+		Count           int    `json:"count"`
+	}
+	if err := json.Unmarshal(request.Arguments, &args); err != nil {
+		return err
+	}
+	start, count, err := server.memoryRange(args.MemoryReference, args.Offset, args.Count)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, count)
+	for i := range data {
+		data[i] = server.vm.ReadMemory(uint16(start + i))
+	}
+	server.respond(request, map[string]interface{}{
+		"address":         fmt.Sprintf("0x%03X", start),
+		"data":            base64.StdEncoding.EncodeToString(data),
+		"unreadableBytes": args.Count - count,
+	})
+	return nil
+}
+
+func (server *dapServer) writeMemory(request *dapMessage) error {
+	var args struct {
+		MemoryReference string `json:"memoryReference"`
+		Offset          int    `json:"offset"`
+		Data            string `json:"data"`
+	}
+	if err := json.Unmarshal(request.Arguments, &args); err != nil {
+		return err
+	}
+	data, err := base64.StdEncoding.DecodeString(args.Data)
+	if err != nil {
+		return err
+	}
+	start, count, err := server.memoryRange(args.MemoryReference, args.Offset, len(data))
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		server.vm.WriteMemory(uint16(start+i), data[i])
+	}
+	server.respond(request, map[string]int{"bytesWritten": count})
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// dapClient : Minimal DAP client for tests
+type dapClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+	seq  int
+}
+
+func (client *dapClient) send(command string, arguments interface{}) {
+	client.seq++
+	body, _ := json.Marshal(map[string]interface{}{"seq": client.seq, "type": "request", "command": command, "arguments": arguments})
+	fmt.Fprintf(client.conn, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// expect : Read messages until the response to command or the event, returning its body
+func (client *dapClient) expect(kind, name string) map[string]interface{} {
+	client.t.Helper()
+	client.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var full struct {
+			Type    string                 `json:"type"`
+			Command string                 `json:"command"`
+			Event   string                 `json:"event"`
+			Success bool                   `json:"success"`
+			Message string                 `json:"message"`
+			Body    map[string]interface{} `json:"body"`
+		}
+		if err := readDAPMessage(client.r, &full); err != nil {
+			client.t.Fatalf("Expected %s %s, got: %v", kind, name, err)
+		}
+		if kind == "event" && full.Type == "event" && full.Event == name ||
+			kind == "response" && full.Type == "response" && full.Command == name {
+			if kind == "response" && !full.Success {
+				client.t.Errorf("Expected %s to succeed, got: %s", name, full.Message)
+			}
+			return full.Body
+		}
+	}
+}
+
+func (client *dapClient) request(command string, arguments interface{}) map[string]interface{} {
+	client.t.Helper()
+	client.send(command, arguments)
+	return client.expect("response", command)
+}
+
+func TestDAP(t *testing.T) {
+	dir := t.TempDir()
+	rom := filepath.Join(dir, "count.ch8")
+	if err := os.WriteFile(rom, []byte{0x60, 0x00, 0x22, 0x08, 0x61, 0x01, 0x12, 0x02, 0x70, 0x01, 0x00, 0xEE}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	symbols := "label 0x200 main\nlabel 0x208 count\n" +
+		"line 0x200 1 count.8o\nline 0x202 2 count.8o\nline 0x204 3 count.8o\nline 0x206 4 count.8o\n" +
+		"line 0x208 7 count.8o\nline 0x20A 8 count.8o\n"
+	if err := os.WriteFile(filepath.Join(dir, "count.sym"), []byte(symbols), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source := filepath.Join(dir, "count.8o")
+
+	server, err := listenDAP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", server.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := &dapClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+
+	client.send("initialize", map[string]string{"adapterID": "chip8go"})
+	client.send("launch", map[string]interface{}{"program": rom, "stopOnEntry": true})
+	filename, err := server.waitLaunch()
+	if err != nil || filename != rom {
+		t.Fatalf("Expected launch of %s, got: %s %v", rom, filename, err)
+	}
+	if body := client.expect("response", "initialize"); body["supportsReadMemoryRequest"] != true {
+		t.Errorf("Expected capabilities incorrect, got: %v", body)
+	}
+	client.expect("response", "launch")
+
+	vm, err := newMachine(chip8.Config{CyclesPerFrame: 10}, readROM(filename), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := newDebugSession(vm, false, "", server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the machine runs on its own goroutine, as in the front end, until the client quits
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		for session.poll() {
+			if !session.debugger.Paused() {
+				vm.RunFrame()
+			}
+			time.Sleep(time.Millisecond)
+		}
+		session.close()
+	}()
+	client.expect("event", "initialized")
+
+	body := client.request("setBreakpoints", map[string]interface{}{
+		"source":      map[string]string{"path": source},
+		"breakpoints": []map[string]int{{"line": 6}, {"line": 20}},
+	})
+	breakpoints := body["breakpoints"].([]interface{})
+	if bp := breakpoints[0].(map[string]interface{}); bp["verified"] != true || bp["line"] != 7.0 || bp["instructionReference"] != "0x208" {
+		t.Errorf("Expected breakpoint moved to line 7, got: %v", bp)
+	}
+	if bp := breakpoints[1].(map[string]interface{}); bp["verified"] != false {
+		t.Errorf("Expected breakpoint past the code unverified, got: %v", bp)
+	}
+
+	client.request("configurationDone", nil)
+	if body := client.expect("event", "stopped"); body["reason"] != "entry" {
+		t.Errorf("Expected stop on entry, got: %v", body)
+	}
+	frames := client.request("stackTrace", map[string]int{"threadId": dapThread})["stackFrames"].([]interface{})
+	if frame := frames[0].(map[string]interface{}); frame["name"] != "main" || frame["line"] != 1.0 {
+		t.Errorf("Expected entry frame incorrect, got: %v", frame)
+	}
+
+	client.request("continue", map[string]int{"threadId": dapThread})
+	if body := client.expect("event", "stopped"); body["reason"] != "breakpoint" {
+		t.Errorf("Expected breakpoint stop, got: %v", body)
+	}
+	frames = client.request("stackTrace", map[string]int{"threadId": dapThread})["stackFrames"].([]interface{})
+	if len(frames) != 2 {
+		t.Fatalf("Expected 2 frames, got: %v", frames)
+	}
+	if frame := frames[0].(map[string]interface{}); frame["name"] != "count" || frame["line"] != 7.0 ||
+		frame["source"].(map[string]interface{})["path"] != source {
+		t.Errorf("Expected subroutine frame incorrect, got: %v", frame)
+	}
+	if frame := frames[1].(map[string]interface{}); frame["name"] != "main+2" || frame["line"] != 2.0 {
+		t.Errorf("Expected caller frame incorrect, got: %v", frame)
+	}
+
+	client.request("scopes", map[string]int{"frameId": 0})
+	if body := client.request("setVariable", map[string]interface{}{"variablesReference": dapRegisters, "name": "V0", "value": "0x2a"}); body["value"] != "0x2A" {
+		t.Errorf("Expected V0 set, got: %v", body)
+	}
+	if body := client.request("evaluate", map[string]string{"expression": "V0 + 1"}); body["result"] != "43 (0x2B)" {
+		t.Errorf("Expected evaluation incorrect, got: %v", body)
+	}
+	if body := client.request("readMemory", map[string]interface{}{"memoryReference": "0x208", "count": 2}); body["data"] != "cAE=" {
+		t.Errorf("Expected memory incorrect, got: %v", body)
+	}
+
+	client.request("stepIn", map[string]int{"threadId": dapThread})
+	if body := client.expect("event", "stopped"); body["reason"] != "step" {
+		t.Errorf("Expected step stop, got: %v", body)
+	}
+	variables := client.request("variables", map[string]int{"variablesReference": dapRegisters})["variables"].([]interface{})
+	if v0 := variables[0].(map[string]interface{}); v0["value"] != "0x2B" {
+		t.Errorf("Expected V0 after step incorrect, got: %v", v0)
+	}
+
+	client.request("disconnect", nil)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected disconnecting to quit the launched ROM")
+	}
+}
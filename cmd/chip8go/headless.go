@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// headless : Run without a window as fast as possible, until the program halts or the budget of
+// frames and instructions runs out (0 for no limit). Keys are pressed by the input script.
+// Starts from the save state file if given, plays and records the input movie (if not nil) and
+// calls onFrame (if not nil) after each frame.
+func headless(config chip8.Config, rombytes []byte, stateFile string, frames int, cycles uint64, script []chip8.KeyEvent, movie *inputMovie, onFrame func(vm *chip8.Machine)) (*chip8.Machine, *chip8.MemoryDisplay, error) {
+	display := &chip8.MemoryDisplay{}
+	keyboard := chip8.NewScriptedKeyboard(script)
+	config.Display = display
+	config.Keyboard = movie.keyboard(keyboard)
+	if config.Audio == nil {
+		config.Audio = &chip8.NullAudio{}
+	}
+	vm, err := newMachine(config, rombytes, stateFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	movie.start(vm)
+
+	for frame := 0; frames == 0 || frame < frames; frame++ {
+		if cycles > 0 && vm.Cycles() >= cycles {
+			break
+		}
+		keyboard.SetFrame(frame)
+		running, err := vm.RunFrame()
+		if onFrame != nil {
+			onFrame(vm)
+		}
+		if err != nil || !running {
+			return vm, display, err
+		}
+	}
+	return vm, display, nil
+}
+
+// runHeadless : Run headless, print the final frame, save the state if asked and return the exit status
+func runHeadless(config chip8.Config, rombytes []byte, states stateFiles, frames int, cycles uint64, script []chip8.KeyEvent, movie *inputMovie, shots *screenshots, video *videoRecording, debug bool) int {
+	if frames == 0 && movie.played != nil {
+		frames = len(movie.played.Keys) // stop where the movie ends
+	}
+	onFrame := func(vm *chip8.Machine) {
+		shots.atFrame(vm)
+		video.capture(vm)
+	}
+	vm, display, err := headless(config, rombytes, states.load, frames, cycles, script, movie, onFrame)
+	if vm == nil {
+		check(err)
+	}
+	check(movie.save())
+	fmt.Print(display)
+	if shots.frame != 0 && !shots.taken {
+		log.Printf("No screenshot, the program stopped before frame %d", shots.frame)
+	}
+	if states.save != "" {
+		check(saveStateFile(vm, states.save))
+	}
+
+	status := finish(vm, err, debug)
+	switch {
+	case err != nil:
+	case vm.Halted() == chip8.NotHalted:
+		log.Printf("Program still running at PC: 0x%x after %d instructions", vm.PC(), vm.Cycles())
+		status = exitRunning
+	case vm.Halted() == chip8.HaltKeyboard:
+		log.Printf("Program waiting for a key at PC: 0x%x after %d instructions", vm.PC(), vm.Cycles())
+		status = exitRunning
+	default:
+		log.Printf("Program halted (%s) at PC: 0x%x after %d instructions", vm.Halted(), vm.PC(), vm.Cycles())
+	}
+	return status
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+func TestHeadless(t *testing.T) {
+	rombytes := readROM("../../roms/programs/IBM Logo.ch8")
+	vm, display, err := headless(chip8.Config{}, rombytes, "", 600, 0, nil, nil, nil)
+
+	if err != nil || vm.Halted() != chip8.HaltSelfJump || exitStatus(vm, err) != exitOK {
+		t.Errorf("Expected halt incorrect, got: %s, %v", vm.Halted(), err)
+	}
+	if !strings.Contains(display.String(), "11111111.111111111") {
+		t.Errorf("Expected screen incorrect, got:\n%s", display)
+	}
+}
+
+func TestHeadlessBudget(t *testing.T) {
+	rombytes := readROM("../../roms/demos/Maze [David Winter, 199x].ch8")
+	vm, _, err := headless(chip8.Config{CyclesPerFrame: 10}, rombytes, "", 0, 100, nil, nil, nil)
+
+	if err != nil || vm.Halted() != chip8.NotHalted || vm.Cycles() != 100 {
+		t.Errorf("Expected budget incorrect, got: %s after %d cycles, %v", vm.Halted(), vm.Cycles(), err)
+	}
+}
+
+func TestHeadlessState(t *testing.T) {
+	rombytes := readROM("../../roms/demos/Maze [David Winter, 199x].ch8")
+	config := chip8.Config{CyclesPerFrame: 10}
+	vm, _, err := headless(config, rombytes, "", 0, 100, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := stateFiles{dir: t.TempDir(), rom: "roms/Maze.ch8"}
+	path := states.slotPath(1)
+	if filepath.Base(path) != "Maze.ch8.1.state" {
+		t.Errorf("Expected slot path incorrect, got: %s", path)
+	}
+	if err := saveStateFile(vm, path); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, _, err := headless(config, rombytes, path, 0, 200, nil, nil, nil)
+	if err != nil || resumed.Cycles() != 200 {
+		t.Errorf("Expected resumed run incorrect, got: %d cycles, %v", resumed.Cycles(), err)
+	}
+	if _, _, err := headless(config, []byte{0x12, 0x00}, path, 0, 200, nil, nil, nil); err == nil {
+		t.Errorf("Expected an error loading the state with a different ROM")
+	}
+}
+
+func TestHeadlessRecordAudio(t *testing.T) {
+	rombytes := readROM("../../roms/demos/Maze [David Winter, 199x].ch8")
+	recording := &audioRecording{tone: chip8.Tone{Volume: 0.5}}
+	path := filepath.Join(t.TempDir(), "maze.wav")
+	if err := recording.start(path); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := headless(chip8.Config{Audio: recording}, rombytes, "", 10, 0, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := recording.stop(); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 44+10*735*2 {
+		t.Errorf("Expected recording size incorrect, got: %v, %v", info, err)
+	}
+}
+
+func TestHeadlessScreenshotAtFrame(t *testing.T) {
+	rombytes := readROM("../../roms/demos/Maze [David Winter, 199x].ch8")
+	dir := t.TempDir()
+	shots := &screenshots{
+		palette: paletteColours([4]uint32{0, 0xFFFFFFFF, 0, 0}),
+		scale:   2,
+		frame:   5,
+		dir:     dir,
+		rom:     "maze.ch8",
+	}
+	if _, _, err := headless(chip8.Config{}, rombytes, "", 10, 0, nil, nil, shots.atFrame); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(dir, "maze.ch8.frame5.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	config, err := png.DecodeConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Width != 128 || config.Height != 64 {
+		t.Errorf("Expected screenshot size incorrect, got: %dx%d", config.Width, config.Height)
+	}
+}
+
+func TestHeadlessRecordVideo(t *testing.T) {
+	rombytes := readROM("../../roms/demos/Maze [David Winter, 199x].ch8")
+	path := filepath.Join(t.TempDir(), "maze.gif")
+	video := &videoRecording{palette: paletteColours([4]uint32{0, 0xFFFFFFFF, 0, 0}), scale: 1}
+	video.start(path)
+	if _, _, err := headless(chip8.Config{}, rombytes, "", 30, 0, nil, nil, video.capture); err != nil {
+		t.Fatal(err)
+	}
+	if err := video.stop(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, delay := range anim.Delay {
+		total += delay
+	}
+	if total != 50 || anim.Config.Width != 64 || anim.Config.Height != 32 {
+		t.Errorf("Expected half a second at 64x32, got: %d at %dx%d", total, anim.Config.Width, anim.Config.Height)
+	}
+}
+
+func TestHeadlessInputMovie(t *testing.T) {
+	rombytes := readROM("../../roms/games/Brix [Andreas Gustafsson, 1990].ch8")
+	path := filepath.Join(t.TempDir(), "brix.movie")
+	script, err := chip8.ParseInputScript("30:+4 90:-4 120:+6 200:-6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recording := &inputMovie{recordPath: path}
+	recorded, display, err := headless(chip8.Config{}, rombytes, "", 300, 0, script, recording, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := recording.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	playing, err := newInputMovie(path, "", rombytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := chip8.Config{Seed: playing.played.Seed}
+	replayed, replayedDisplay, err := headless(config, rombytes, "", 300, 0, nil, playing, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.PC() != recorded.PC() || replayed.Cycles() != recorded.Cycles() || replayedDisplay.String() != display.String() {
+		t.Errorf("Expected replay to match the recorded run, got PC: 0x%x, want: 0x%x", replayed.PC(), recorded.PC())
+	}
+	if !playing.player.Done() {
+		t.Error("Expected movie to be played to the end")
+	}
+
+	if _, err := newInputMovie(path, "", []byte{0x12, 0x00}); err == nil {
+		t.Error("Expected error playing a movie of another ROM")
+	}
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/jamesmcm/chip8go/chip8"
+	"github.com/vharitonsky/iniflags"
+)
+
+func main() {
+	platform := flag.String("platform", "chip-8",
+		"Platform to emulate: chip-8, schip, xo-chip (default: chip-8)")
+	vipHires := flag.String("vip-hires", "auto",
+		"COSMAC VIP two-page 64x64 hires mode: auto, on, off (default: auto)")
+	quirksPreset := flag.String("quirks", "",
+		"Quirks preset: vip, schip-legacy, schip-modern, xo-chip (default: from -platform)")
+	quirkShift := flag.Bool("quirk-shift", false,
+		"8xy6/8xyE shift Vx in place, ignoring Vy (default: from -quirks)")
+	quirkLoadStore := flag.Bool("quirk-load-store", false,
+		"Fx55/Fx65 leave I unchanged (default: from -quirks)")
+	quirkJump := flag.Bool("quirk-jump", false,
+		"Bxnn jumps to xnn + Vx instead of nnn + V0 (default: from -quirks)")
+	quirkVFReset := flag.Bool("quirk-vf-reset", false,
+		"8xy1/8xy2/8xy3 reset VF to 0 (default: from -quirks)")
+	quirkClip := flag.Bool("quirk-clip", false,
+		"Clip sprites at the screen edges instead of wrapping (default: from -quirks)")
+	quirkDisplayWait := flag.Bool("quirk-display-wait", false,
+		"Dxyn waits for the next 60Hz vertical blank (default: from -quirks)")
+	wrapX := flag.String("wrapX", "",
+		"Wrap screen horizontally: on, off, clip, error (default: from -quirk-clip)")
+	wrapY := flag.String("wrapY", "",
+		"Wrap screen vertically: on, off, clip, error (default: from -quirk-clip)")
+	clockSpeed := flag.Int("clock-speed", 1300,
+		"Approximate cycle speed in Hz, used when -cycles-per-frame is 0 (default: 1300)")
+	timerSpeed := flag.Int("timer-speed", 60,
+		"Timer and frame speed in Hz (default: 60)")
+	cyclesPerFrame := flag.Int("cycles-per-frame", 0,
+		"Instructions run per frame (default: clock-speed / timer-speed)")
+	maxFrameSkip := flag.Int("max-frame-skip", 5,
+		"Maximum frames run without rendering to catch up when running behind (default: 5)")
+	rewindSeconds := flag.Int("rewind-seconds", 180,
+		"Seconds of play kept for rewinding with the REWIND key, 0 to disable (default: 180)")
+	screenBuffer := flag.Int("screen-buffer", 1,
+		"Number of frames to merge for output to prevent flickering (default: 1)")
+	stackDepth := flag.Int("stack-depth", chip8.StackDepthSCHIP,
+		"Maximum nested subroutine calls: 12 for the COSMAC VIP, 16 for SCHIP, -1 for unlimited (default: 16)")
+	scalingFactor := flag.Int("scaling-factor", 8,
+		"Scaling factor for pixels (sets screen size) (default: 8)")
+	fgColour := flag.String("fg", "0xFFFFFFFF",
+		"Colour for foreground (active pixels) as hexadecimal string (default: 0xFFFFFFFF)")
+	bgColour := flag.String("bg", "0x00000000",
+		"Colour for background (active pixels) as hexadecimal string (default: 0x00000000)")
+	fg2Colour := flag.String("fg2", "0xFFFF6600",
+		"Colour for pixels only active on the second XO-CHIP plane as hexadecimal string (default: 0xFFFF6600)")
+	blendColour := flag.String("blend", "0xFF662200",
+		"Colour for pixels active on both XO-CHIP planes as hexadecimal string (default: 0xFF662200)")
+	faultPolicy := flag.String("fault-policy", "halt",
+		"Action on runtime faults: halt, ignore, log, break, optionally per fault e.g. \"log,stack-underflow=halt\" (default: halt)")
+	headless := flag.Bool("headless", false,
+		"Run without a window as fast as possible, printing the final frame (default: false)")
+	frames := flag.Int("frames", 0,
+		"Headless frame budget, and frames to record with -record, 0 for no limit (default: 0)")
+	cycles := flag.Int("cycles", 0,
+		"Headless instruction budget, checked at the end of each frame, 0 for no limit (default: 0)")
+	input := flag.String("input", "",
+		"Headless key script, e.g. \"60:5 120:+4 180:-4\" taps 5 at frame 60 and holds 4 from frame 120 to 180")
+	recordInput := flag.String("record-input", "",
+		"Record the keys held each frame, the random seed and the ROM hash to an input movie file, to replay the run exactly (default: off)")
+	playInput := flag.String("play-input", "",
+		"Replay an input movie file, then carry on with live input (default: off)")
+	seed := flag.Int64("seed", 0,
+		"Seed for Cxkk random numbers, 0 for a seed from the time (default: 0)")
+	loadState := flag.String("load-state", "",
+		"Start from a save state file made with the same ROM")
+	saveState := flag.String("save-state", "",
+		"Write a save state file on exit")
+	stateDir := flag.String("state-dir", "",
+		"Directory for the F1-F9 quick-save slots (default: the ROM's directory)")
+	soundFrequency := flag.Float64("sound-frequency", 440,
+		"Frequency of the sound timer's tone in Hz (default: 440)")
+	soundWaveform := flag.String("sound-waveform", chip8.WaveSquare,
+		"Waveform of the sound timer's tone: square, triangle, sawtooth, sine (default: square)")
+	volume := flag.Int("volume", 50,
+		"Volume of the sound timer's tone, 0-100 (default: 50)")
+	mute := flag.Bool("mute", false,
+		"Silence the sound timer's tone (default: false)")
+	record := flag.String("record", "",
+		"Record gameplay to an animated GIF, or APNG for .png and .apng files, a frame each timer tick (default: off)")
+	recordScale := flag.Int("record-scale", 1,
+		"Scaling factor for pixels in recorded video, 1 for the native resolution (default: 1)")
+	recordAudio := flag.String("record-audio", "",
+		"Record the sound timer's tone to a WAV file, generated from the timer each frame so runs record identical audio (default: off)")
+	screenshot := flag.String("screenshot", "",
+		"PNG file for the -screenshot-at-frame screenshot (default: named after the ROM and frame)")
+	screenshotAtFrame := flag.Int("screenshot-at-frame", 0,
+		"Save a PNG screenshot once the program has run this many frames, also headless (default: off)")
+	screenshotScale := flag.Int("screenshot-scale", 1,
+		"Scaling factor for pixels in screenshots, 1 for the native resolution (default: 1)")
+	debug := flag.Bool("debug", false, "Produce output for debugging")
+	debugger := flag.Bool("debugger", false,
+		"Start paused in the interactive debugger, with commands typed in the terminal (default: false)")
+	gdb := flag.String("gdb", "",
+		"Serve the GDB remote protocol on a TCP address, e.g. localhost:2345 (default: off)")
+	dapAddr := flag.String("dap", "",
+		"Serve the Debug Adapter Protocol on a TCP address for IDEs to attach, e.g. localhost:4711; with the dap command, for IDEs to launch ROMs instead of stdio (default: off)")
+	output := flag.String("o", "",
+		"Write the ROM compiled from a .8o Octo program to a file, instead of running it (default: off)")
+	useROMDB := flag.Bool("romdb", true,
+		"Apply recommended options from the ROM database, explicit flags take precedence (default: true)")
+	commandLine := commandLineFlags(flag.CommandLine, os.Args[1:])
+	iniflags.Parse()
+
+	filename := flag.Arg(0)
+	switch filename {
+	case "disasm":
+		fmt.Print(chip8.DisassembleROM(readROM(flag.Arg(1))))
+		return
+	case "asm":
+		rom, err := assembleFile(flag.Arg(1))
+		check(err)
+		log.Printf("Assembled %s", rom)
+		return
+	}
+	var dap *dapServer
+	var err error
+	switch {
+	case filename == "dap" && *dapAddr == "":
+		if *debugger {
+			log.Fatal("the debugger console can't share stdio with the dap command, use -dap to listen on TCP")
+		}
+		dap = stdioDAP(os.Stdin, os.Stdout)
+		os.Stdout = os.Stderr // stdout is for the protocol, send everything else to stderr
+	case filename == "dap" || *dapAddr != "":
+		dap, err = listenDAP(*dapAddr)
+		check(err)
+		log.Printf("DAP server listening on %s", dap.listener.Addr())
+	}
+	if filename == "dap" {
+		filename, err = dap.waitLaunch()
+		check(err)
+	} else if dap != nil {
+		dap.program = filename
+	}
+	var rombytes []byte
+	switch {
+	case isOcto(filename):
+		var symbols *chip8.Symbols
+		rombytes, symbols, err = compileOcto(filename)
+		check(err)
+		if *output != "" {
+			check(os.WriteFile(*output, rombytes, 0644))
+			log.Printf("Compiled %s", *output)
+			return
+		}
+		if dap != nil {
+			dap.compiled = symbols
+		}
+	case *output != "":
+		log.Fatalf("-o compiles .8o programs, not %s", filename)
+	default:
+		rombytes = readROM(filename)
+	}
+
+	var romKeys map[string]uint8
+	if *useROMDB {
+		if info, ok := lookupROM(rombytes); ok {
+			applyROMInfo(info, commandLine)
+			printROMInfo(os.Stderr, info)
+			romKeys = info.hostKeys()
+		}
+	}
+
+	switch *platform {
+	case chip8.PlatformChip8, chip8.PlatformSCHIP, chip8.PlatformXOChip:
+	default:
+		log.Fatalf("unknown platform: %s", *platform)
+	}
+
+	quirks := chip8.PlatformQuirks(*platform)
+	if *quirksPreset != "" {
+		var ok bool
+		quirks, ok = chip8.QuirksPresets[*quirksPreset]
+		if !ok {
+			log.Fatalf("unknown quirks preset: %s", *quirksPreset)
+		}
+	}
+	// Only override the preset with quirks that were set explicitly
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "quirk-shift":
+			quirks.Shift = *quirkShift
+		case "quirk-load-store":
+			quirks.LoadStore = *quirkLoadStore
+		case "quirk-jump":
+			quirks.Jump = *quirkJump
+		case "quirk-vf-reset":
+			quirks.VFReset = *quirkVFReset
+		case "quirk-clip":
+			quirks.Clip = *quirkClip
+		case "quirk-display-wait":
+			quirks.DisplayWait = *quirkDisplayWait
+		}
+	})
+
+	faultPolicies, err := chip8.ParseFaultPolicies(*faultPolicy)
+	check(err)
+
+	fg, err := strconv.ParseUint(*fgColour, 0, 32)
+	check(err)
+	bg, err := strconv.ParseUint(*bgColour, 0, 32)
+	check(err)
+	fg2, err := strconv.ParseUint(*fg2Colour, 0, 32)
+	check(err)
+	blend, err := strconv.ParseUint(*blendColour, 0, 32)
+	check(err)
+
+	if *debug {
+		chip8.PrintROM(rombytes)
+	}
+
+	config := chip8.Config{
+		Platform:       *platform,
+		VIPHires:       *vipHires,
+		Quirks:         quirks,
+		WrapX:          *wrapX,
+		WrapY:          *wrapY,
+		ClockSpeed:     *clockSpeed,
+		TimerSpeed:     *timerSpeed,
+		CyclesPerFrame: *cyclesPerFrame,
+		ScreenBuffer:   *screenBuffer,
+		FaultPolicy:    faultPolicies,
+		StackDepth:     *stackDepth,
+		Seed:           *seed,
+	}
+	movie, err := newInputMovie(*playInput, *recordInput, rombytes)
+	check(err)
+	if movie.active() {
+		if *loadState != "" {
+			log.Fatal("input movies replay runs from the start of the ROM, they can't be used with -load-state")
+		}
+		if movie.played != nil {
+			config.Seed = movie.played.Seed
+		}
+	}
+	states := stateFiles{load: *loadState, save: *saveState, dir: *stateDir, rom: filename}
+	if !chip8.ValidWaveform(*soundWaveform) {
+		log.Fatalf("unknown waveform: %s", *soundWaveform)
+	}
+	if *volume < 0 || *volume > 100 {
+		log.Fatalf("volume out of range: %d", *volume)
+	}
+	tone := chip8.Tone{
+		Frequency: *soundFrequency,
+		Waveform:  *soundWaveform,
+		Volume:    float64(*volume) / 100,
+		Muted:     *mute,
+	}
+	recording := &audioRecording{tone: tone, timerSpeed: *timerSpeed, dir: *stateDir, rom: filename}
+	recording.tone.Muted = false // muting is only for what is played
+	if *recordAudio != "" {
+		check(recording.start(*recordAudio))
+	}
+	if *screenshotAtFrame < 0 {
+		log.Fatalf("screenshot frame out of range: %d", *screenshotAtFrame)
+	}
+	palette := [4]uint32{uint32(bg), uint32(fg), uint32(fg2), uint32(blend)}
+	shots := &screenshots{
+		palette: paletteColours(palette),
+		scale:   *screenshotScale,
+		frame:   uint64(*screenshotAtFrame),
+		path:    *screenshot,
+		dir:     *stateDir,
+		rom:     filename,
+	}
+	video := &videoRecording{
+		palette: shots.palette,
+		scale:   *recordScale,
+		frames:  *frames,
+		dir:     *stateDir,
+		rom:     filename,
+	}
+	if *record != "" {
+		video.start(*record)
+	}
+	if *headless {
+		if *debugger || *gdb != "" || dap != nil {
+			log.Fatal("the debugger needs the window, it can't be used with -headless")
+		}
+		script, err := chip8.ParseInputScript(*input)
+		check(err)
+		config.Audio = recording
+		status := runHeadless(config, rombytes, states, *frames, uint64(*cycles), script, movie, shots, video, *debug)
+		check(recording.stop())
+		check(video.stop())
+		os.Exit(status)
+	}
+	window := windowOptions{
+		scalingFactor: int32(*scalingFactor),
+		palette:       palette,
+		maxFrameSkip:  *maxFrameSkip,
+		rewindSeconds: *rewindSeconds,
+		debugger:      *debugger,
+		gdb:           *gdb,
+		dap:           dap,
+		tone:          tone,
+		recording:     recording,
+		screenshots:   shots,
+		video:         video,
+		movie:         movie,
+		keys:          romKeys,
+	}
+	os.Exit(runSDL(config, rombytes, states, window, *debug))
+}
+
+// windowOptions : Options for running in an SDL window
+type windowOptions struct {
+	scalingFactor int32
+	palette       [4]uint32 // background, foreground, XO-CHIP second plane and both planes
+	maxFrameSkip  int
+	rewindSeconds int              // 0 disables rewinding
+	debugger      bool             // start paused in the debugger console
+	gdb           string           // GDB server address, empty to disable
+	dap           *dapServer       // nil without the dap command or -dap
+	tone          chip8.Tone       // sound timer output
+	recording     *audioRecording  // records the sound timer output, toggled by the record key
+	screenshots   *screenshots     // taken at a frame and by the screenshot key
+	video         *videoRecording  // records gameplay, toggled by the video key
+	movie         *inputMovie      // input movie played or recorded
+	keys          map[string]uint8 // extra SDL key names to CHIP-8 keys from the ROM database
+}
+
+// Exit statuses reflecting how the program ended
+const (
+	exitOK      = 0 // exited with 00FD, halted, or quit
+	exitError   = 1 // chip8go error, e.g. bad options or a missing ROM
+	exitFault   = 2 // stopped on a runtime fault
+	exitRunning = 3 // headless program still running when the budget ran out, or waiting for input the script doesn't give
+)
+
+// exitStatus : Process exit status for how the program ended
+func exitStatus(vm *chip8.Machine, err error) int {
+	if err != nil || vm.Halted() == chip8.HaltFault {
+		return exitFault
+	}
+	return exitOK
+}
+
+// finish : Print the machine state for debugging or on a fault, and return the exit status
+func finish(vm *chip8.Machine, err error, debug bool) int {
+	if debug || err != nil {
+		vm.PrintState()
+	}
+	if err != nil {
+		log.Print(err)
+	}
+	return exitStatus(vm, err)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// inputMovie : Input movie played with -play-input and/or recorded with -record-input
+// When both are given the recording starts with the movie played, and carries on from there.
+type inputMovie struct {
+	played     *chip8.Movie // nil when not playing
+	recordPath string       // empty when not recording
+	recorder   *chip8.MovieRecorder
+	player     *chip8.MoviePlayer
+}
+
+// newInputMovie : Read the movie to play, if any, checking it was recorded with the ROM
+func newInputMovie(playPath string, recordPath string, rombytes []byte) (*inputMovie, error) {
+	movie := &inputMovie{recordPath: recordPath}
+	if playPath == "" {
+		return movie, nil
+	}
+	f, err := os.Open(playPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	movie.played, err = chip8.ReadMovie(f)
+	if err == nil {
+		err = movie.played.Check(rombytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("playing %s: %v", playPath, err)
+	}
+	return movie, nil
+}
+
+// active : Whether a movie is being played or recorded
+func (movie *inputMovie) active() bool {
+	return movie != nil && (movie.played != nil || movie.recordPath != "")
+}
+
+// keyboard : Keyboard playing and recording the movie, with live input from keyboard
+// The machine must be created with the seed of the movie played, and then passed to start.
+func (movie *inputMovie) keyboard(live chip8.Keyboard) chip8.Keyboard {
+	if !movie.active() {
+		return live
+	}
+	keyboard := live
+	if movie.played != nil {
+		movie.player = chip8.NewMoviePlayer(movie.played, live)
+		keyboard = movie.player
+	}
+	if movie.recordPath != "" {
+		movie.recorder = chip8.NewMovieRecorder(keyboard, nil)
+		keyboard = movie.recorder
+	}
+	return keyboard
+}
+
+// start : Start recording the run of vm, before it runs any frames
+func (movie *inputMovie) start(vm *chip8.Machine) {
+	if movie != nil && movie.recorder != nil {
+		movie.recorder.Movie = chip8.NewMovie(vm)
+	}
+}
+
+// save : Write the recorded movie, if recording
+func (movie *inputMovie) save() error {
+	if movie == nil || movie.recorder == nil {
+		return nil
+	}
+	f, err := os.Create(movie.recordPath)
+	if err != nil {
+		return err
+	}
+	err = movie.recorder.Movie.Write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("saving input movie to %s: %v", movie.recordPath, err)
+	}
+	log.Printf("Saved %d frames of input to %s", len(movie.recorder.Movie.Keys), movie.recordPath)
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build nosdl
+
+package main
+
+import (
+	"log"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// runSDL : Built with the nosdl tag, so only headless runs are available
+func runSDL(config chip8.Config, rombytes []byte, states stateFiles, window windowOptions, debug bool) int {
+	log.Print("chip8go was built without SDL, use -headless")
+	return exitError
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// isOcto : Whether a program is Octo source, to be compiled before it is run
+func isOcto(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".8o")
+}
+
+// compileOcto : Compile an Octo source file into a ROM and its symbols
+func compileOcto(source string) ([]byte, *chip8.Symbols, error) {
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return chip8.CompileOcto(filepath.Base(source), f)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// audioRecording : Sound timer output, also recorded to a WAV file while a recording is running
+type audioRecording struct {
+	out        chip8.Audio
+	tone       chip8.Tone
+	timerSpeed int
+	dir        string // directory for recordings started without a path, the ROM's directory when empty
+	rom        string // ROM filename, recordings started without a path are named after it
+	file       *os.File
+	recorder   *chip8.WAVRecorder
+}
+
+// SoundTick : Play and record the tick
+func (recording *audioRecording) SoundTick(playing bool) {
+	if recording.out != nil {
+		recording.out.SoundTick(playing)
+	}
+	if recording.recorder != nil {
+		recording.recorder.SoundTick(playing)
+	}
+}
+
+// PatternTick : Play and record the tick of an XO-CHIP audio pattern
+func (recording *audioRecording) PatternTick(playing bool, pattern [16]uint8, rate float64) {
+	if out, ok := recording.out.(chip8.PatternAudio); ok {
+		out.PatternTick(playing, pattern, rate)
+	} else if recording.out != nil {
+		recording.out.SoundTick(playing)
+	}
+	if recording.recorder != nil {
+		recording.recorder.PatternTick(playing, pattern, rate)
+	}
+}
+
+// start : Start recording to a WAV file, by default named after the ROM and the time
+func (recording *audioRecording) start(path string) error {
+	if path == "" {
+		path = namedAfterROM(recording.dir, recording.rom, time.Now().Format("20060102-150405")+".wav")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	recording.recorder, err = chip8.NewWAVRecorder(f, recording.tone, recording.timerSpeed)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	recording.file = f
+	log.Printf("Recording audio to %s", path)
+	return nil
+}
+
+// stop : Finish the recording, if one is running
+func (recording *audioRecording) stop() error {
+	if recording.recorder == nil {
+		return nil
+	}
+	err := recording.recorder.Close()
+	if closeErr := recording.file.Close(); err == nil {
+		err = closeErr
+	}
+	path := recording.file.Name()
+	recording.file, recording.recorder = nil, nil
+	if err != nil {
+		return fmt.Errorf("recording audio to %s: %v", path, err)
+	}
+	log.Printf("Saved audio to %s", path)
+	return nil
+}
+
+// toggle : Start or stop recording, for the record key
+func (recording *audioRecording) toggle() {
+	var err error
+	if recording.recorder != nil {
+		err = recording.stop()
+	} else {
+		err = recording.start("")
+	}
+	if err != nil {
+		log.Print(err)
+	}
+}
+
+// videoRecording : Gameplay recorded to an animated GIF or APNG file, a frame each timer tick
+type videoRecording struct {
+	palette   [4]color.Color
+	scale     int
+	frames    int    // frames to record before stopping, 0 for no limit
+	dir       string // directory for recordings started without a path, the ROM's directory when empty
+	rom       string // ROM filename, recordings started without a path are named after it
+	path      string
+	animation *chip8.Animation
+}
+
+// start : Start recording to a file, APNG for .png and .apng files and otherwise GIF, by default
+// a GIF named after the ROM and the time
+func (video *videoRecording) start(path string) {
+	if path == "" {
+		path = namedAfterROM(video.dir, video.rom, time.Now().Format("20060102-150405")+".gif")
+	}
+	video.path = path
+	video.animation = chip8.NewAnimation(video.palette, video.scale)
+	log.Printf("Recording video to %s", path)
+}
+
+// capture : Record the frame, if a recording is running, stopping once it has enough frames
+func (video *videoRecording) capture(vm *chip8.Machine) {
+	if video.animation == nil {
+		return
+	}
+	video.animation.Capture(vm)
+	if video.frames > 0 && video.animation.Ticks() >= video.frames {
+		if err := video.stop(); err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+// stop : Write the recording, if one is running
+func (video *videoRecording) stop() error {
+	if video.animation == nil {
+		return nil
+	}
+	animation, path := video.animation, video.path
+	video.animation = nil
+	if animation.Ticks() == 0 {
+		log.Printf("No video recorded to %s, no frames were run", path)
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".apng":
+		err = animation.EncodeAPNG(f)
+	default:
+		err = animation.EncodeGIF(f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("recording video to %s: %v", path, err)
+	}
+	log.Printf("Saved %d frames of video to %s", animation.Ticks(), path)
+	return nil
+}
+
+// toggle : Start or stop recording, for the video key
+func (video *videoRecording) toggle() {
+	if video.animation == nil {
+		video.start("")
+		return
+	}
+	if err := video.stop(); err != nil {
+		log.Print(err)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	_ "embed" // embeds romdb.json
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// romdbJSON : ROM database of recommended options keyed by the ROM's SHA-1
+//
+//go:embed romdb.json
+var romdbJSON []byte
+
+// romInfo : ROM database entry
+type romInfo struct {
+	Title      string            `json:"title"`
+	Platform   string            `json:"platform,omitempty"`    // chip-8, schip or xo-chip
+	Quirks     string            `json:"quirks,omitempty"`      // quirk preset for the platform the ROM was written for
+	ClockSpeed int               `json:"clock-speed,omitempty"` // cycle speed in Hz
+	Colours    map[string]string `json:"colours,omitempty"`     // colour flag (fg, bg, fg2, blend) to its value
+	Options    map[string]string `json:"options,omitempty"`     // other flag names to recommended values
+	Keys       map[string]string `json:"keys,omitempty"`        // CHIP-8 key (0-F) to its use in the ROM
+}
+
+// lookupROM : Find the ROM in the embedded database
+func lookupROM(rombytes []byte) (romInfo, bool) {
+	var db map[string]romInfo
+	check(json.Unmarshal(romdbJSON, &db))
+	info, ok := db[chip8.ROMHash(rombytes)]
+	return info, ok
+}
+
+// options : All the recommended options as flag names to values
+func (info romInfo) options() map[string]string {
+	options := make(map[string]string)
+	for name, value := range info.Options {
+		options[name] = value
+	}
+	for name, value := range info.Colours {
+		options[name] = value
+	}
+	if info.Platform != "" {
+		options["platform"] = info.Platform
+	}
+	if info.Quirks != "" {
+		options["quirks"] = info.Quirks
+	}
+	if info.ClockSpeed != 0 {
+		options["clock-speed"] = strconv.Itoa(info.ClockSpeed)
+	}
+	return options
+}
+
+// hostKeys : SDL key names to the CHIP-8 keys the ROM uses for directions, so the arrow keys work too
+func (info romInfo) hostKeys() map[string]uint8 {
+	names := map[string]string{"up": "Up", "down": "Down", "left": "Left", "right": "Right"}
+	keys := make(map[string]uint8)
+	for k, use := range info.Keys {
+		name, ok := names[use]
+		if !ok {
+			continue
+		}
+		key, err := strconv.ParseUint(k, 16, 4)
+		if err != nil {
+			continue
+		}
+		keys[name] = uint8(key)
+	}
+	return keys
+}
+
+// commandLineFlags : Names of the flags given in args, parsed like the flag package does
+//
+// iniflags sets the values from the config file with flag.Set, so flag.Visit can't tell them
+// apart from the command-line; this must run on os.Args before iniflags.Parse.
+func commandLineFlags(flags *flag.FlagSet, args []string) map[string]bool {
+	explicit := make(map[string]bool)
+	for len(args) > 0 {
+		arg := args[0]
+		args = args[1:]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			break // the flag package stops at the first non-flag argument
+		}
+		name := strings.TrimPrefix(arg[1:], "-")
+		name, _, hasValue := strings.Cut(name, "=")
+		explicit[name] = true
+		f := flags.Lookup(name)
+		if f == nil || hasValue {
+			continue
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			continue
+		}
+		if len(args) > 0 {
+			args = args[1:] // the flag's value
+		}
+	}
+	return explicit
+}
+
+// applyROMInfo : Set the recommended options for a ROM, flags given on the command-line are not changed
+func applyROMInfo(info romInfo, explicit map[string]bool) {
+	for name, value := range info.options() {
+		if !explicit[name] {
+			check(flag.Set(name, value))
+		}
+	}
+}
+
+// printROMInfo : Print the ROM title and key hints, to stderr so headless stdout is only the frame
+func printROMInfo(w io.Writer, info romInfo) {
+	fmt.Fprintln(w, info.Title)
+	var keys []string
+	for k := range info.Keys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %s: %s\n", k, info.Keys[k])
+	}
+}
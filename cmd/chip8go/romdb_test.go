@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+func TestLookupROM(t *testing.T) {
+	rombytes := readROM("../../roms/hires/Hires Maze [David Winter, 199x].ch8")
+	info, ok := lookupROM(rombytes)
+
+	if !ok {
+		t.Fatalf("ROM not found in database, hash: %s", chip8.ROMHash(rombytes))
+	}
+	if info.Title != "Hires Maze [David Winter, 199x]" {
+		t.Errorf("ROM title incorrect, got: %s", info.Title)
+	}
+	if info.Platform != chip8.PlatformChip8 {
+		t.Errorf("ROM platform incorrect, got: %s", info.Platform)
+	}
+}
+
+func TestROMInfoOptions(t *testing.T) {
+	info, ok := lookupROM(readROM("../../roms/games/Blinky [Hans Christian Egeberg, 1991].ch8"))
+	if !ok {
+		t.Fatal("ROM not found in database")
+	}
+	want := map[string]string{"platform": "chip-8", "quirks": "schip-legacy", "fg": "0xFF1E2418", "bg": "0xFF98A890"}
+	if got := info.options(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ROM options incorrect, got: %v expected: %v", got, want)
+	}
+
+	info, _ = lookupROM(readROM("../../roms/games/Rocket [Joseph Weisbecker, 1978].ch8"))
+	want = map[string]string{"platform": "chip-8", "quirks": "vip", "clock-speed": "600"}
+	if got := info.options(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ROM options incorrect, got: %v expected: %v", got, want)
+	}
+}
+
+func TestROMInfoHostKeys(t *testing.T) {
+	info, ok := lookupROM(readROM("../../roms/games/Tetris [Fran Dachille, 1991].ch8"))
+	if !ok {
+		t.Fatal("ROM not found in database")
+	}
+	want := map[string]uint8{"Left": 0x5, "Right": 0x6}
+	if got := info.hostKeys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ROM host keys incorrect, got: %v expected: %v", got, want)
+	}
+}
+
+func TestCommandLineFlags(t *testing.T) {
+	flags := flag.NewFlagSet("chip8go", flag.ContinueOnError)
+	flags.String("platform", "chip-8", "")
+	flags.String("fg", "0xFFFFFFFF", "")
+	flags.Bool("mute", false, "")
+	flags.Bool("romdb", true, "")
+	flags.Int("clock-speed", 1300, "")
+	// Values from the config file are set like this, and must not count as explicit
+	check(flags.Set("fg", "0xFF000000"))
+
+	args := []string{"-romdb=false", "--platform", "schip", "-mute", "-clock-speed=900", "rom.ch8", "-bg", "0"}
+	got := commandLineFlags(flags, args)
+	want := map[string]bool{"romdb": true, "platform": true, "mute": true, "clock-speed": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Command-line flags incorrect, got: %v expected: %v", got, want)
+	}
+	check(flags.Parse(args))
+	if flags.NArg() != 3 {
+		t.Errorf("Flag package parsed the arguments differently, remaining: %v", flags.Args())
+	}
+
+	if got := commandLineFlags(flags, []string{"-platform", "-mute"}); !reflect.DeepEqual(got, map[string]bool{"platform": true}) {
+		t.Errorf("Flag value taken as a flag, got: %v", got)
+	}
+	if got := commandLineFlags(flags, []string{"--", "-mute"}); len(got) != 0 {
+		t.Errorf("Flags after -- included, got: %v", got)
+	}
+}
+
+func TestLookupROM_unknown(t *testing.T) {
+	if _, ok := lookupROM([]byte{0x12, 0x00}); ok {
+		t.Errorf("Unknown ROM found in database")
+	}
+}
+
+func TestPrintROMInfo(t *testing.T) {
+	var buf bytes.Buffer
+	printROMInfo(&buf, romInfo{Title: "Pong", Keys: map[string]string{"4": "down", "1": "up"}})
+	if want := "Pong\n  1: up\n  4: down\n"; buf.String() != want {
+		t.Errorf("ROM info incorrect, got: %q expected: %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"time"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// screenshots : Screenshot options from the command line
+type screenshots struct {
+	palette [4]color.Color
+	scale   int
+	frame   uint64 // frame to take a screenshot at, 0 for none
+	path    string // file for the screenshot at frame, named after the ROM and frame when empty
+	dir     string // directory for screenshots named after the ROM, the ROM's directory when empty
+	rom     string // ROM filename
+	taken   bool   // the screenshot at frame has been taken
+}
+
+// paletteColours : Opaque colours of 0xAARRGGBB palette entries, as the window shows them
+func paletteColours(palette [4]uint32) [4]color.Color {
+	var colours [4]color.Color
+	for i, c := range palette {
+		colours[i] = color.RGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 0xFF}
+	}
+	return colours
+}
+
+// take : Write the screen to a PNG file, by default named after the ROM and the time
+func (shots *screenshots) take(vm *chip8.Machine, path string) error {
+	if path == "" {
+		path = namedAfterROM(shots.dir, shots.rom, time.Now().Format("20060102-150405.000")+".png")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, vm.Screenshot(shots.palette, shots.scale))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("saving screenshot to %s: %v", path, err)
+	}
+	log.Printf("Saved screenshot to %s", path)
+	return nil
+}
+
+// atFrame : Take the screenshot at frame, once the machine has reached it
+func (shots *screenshots) atFrame(vm *chip8.Machine) {
+	if shots.frame == 0 || shots.taken || vm.Frames() < shots.frame {
+		return
+	}
+	shots.taken = true
+	path := shots.path
+	if path == "" {
+		path = namedAfterROM(shots.dir, shots.rom, fmt.Sprintf("frame%d.png", shots.frame))
+	}
+	if err := shots.take(vm, path); err != nil {
+		log.Print(err)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build !nosdl
+
+package main
+
+import "github.com/veandco/go-sdl2/sdl"
+
+type SDLDisplay struct {
+	surface       *sdl.Surface
+	window        *sdl.Window
+	scalingFactor int32
+	pixelSize     int32
+	palette       [4]uint32 // background, foreground, XO-CHIP second plane and both planes
+}
+
+// SDLInit : Initialise SDL window with scaling factor and colour palette
+func (display *SDLDisplay) init(scalingFactor int32, palette [4]uint32) {
+	err := sdl.Init(sdl.INIT_EVERYTHING)
+	check(err)
+	display.window, err = sdl.CreateWindow("chip8go", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		scalingFactor*64, scalingFactor*32, sdl.WINDOW_SHOWN)
+	check(err)
+
+	display.surface, err = display.window.GetSurface()
+	check(err)
+	err = display.surface.FillRect(nil, palette[0])
+	check(err)
+
+	display.palette = palette
+	display.scalingFactor = scalingFactor
+	display.pixelSize = scalingFactor
+}
+
+// SetResolution : Resize the window for a width x height display
+// Pixels are shrunk for wider modes so 64 and 128 column modes share a window width.
+func (display *SDLDisplay) SetResolution(width int32, height int32) {
+	display.pixelSize = display.scalingFactor * 64 / width
+	if display.pixelSize < 1 {
+		display.pixelSize = 1
+	}
+	display.window.SetSize(display.pixelSize*width, display.pixelSize*height)
+
+	var err error
+	display.surface, err = display.window.GetSurface()
+	check(err)
+}
+
+func (display *SDLDisplay) DrawPixel(x int32, y int32, colour uint8) {
+	rect := sdl.Rect{
+		X: x * display.pixelSize,
+		Y: y * display.pixelSize,
+		W: display.pixelSize,
+		H: display.pixelSize}
+	err := display.surface.FillRect(&rect, display.palette[colour])
+	check(err)
+}
+func (display *SDLDisplay) ClearDisplay() {
+	err := display.surface.FillRect(nil, display.palette[0])
+	check(err)
+
+}
+
+func (display *SDLDisplay) UpdateDisplay() {
+	err := display.window.UpdateSurface()
+	check(err)
+}
+
+func (display *SDLDisplay) Destroy() {
+	err := display.window.Destroy()
+	check(err)
+}
@@ -0,0 +1,79 @@
+//go:build !nosdl
+
+package main
+
+import (
+	"encoding/binary"
+
+	"github.com/jamesmcm/chip8go/chip8"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SDLAudio : Sound timer output as a continuous tone or XO-CHIP audio pattern, queued to an SDL audio device a tick at a time
+type SDLAudio struct {
+	device  sdl.AudioDeviceID
+	tone    chip8.Tone
+	tick    int // samples per timer tick
+	samples []int16
+	data    []byte
+}
+
+// newSDLAudio : Open the default audio device for a tone, at 60 timer ticks per second until tick is set
+func newSDLAudio(tone chip8.Tone) (*SDLAudio, error) {
+	spec := sdl.AudioSpec{
+		Freq:     int32(tone.Rate()),
+		Format:   sdl.AUDIO_S16LSB,
+		Channels: 1,
+		Samples:  512,
+	}
+	device, err := sdl.OpenAudioDevice("", false, &spec, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	audio := &SDLAudio{device: device, tone: tone, tick: tone.Rate() / 60}
+	sdl.PauseAudioDevice(device, false)
+	return audio, nil
+}
+
+// SoundTick : Queue a tick of the tone, or of silence
+func (audio *SDLAudio) SoundTick(playing bool) {
+	audio.queue(func(samples []int16) {
+		audio.tone.Samples(samples, playing)
+	})
+}
+
+// PatternTick : Queue a tick of an XO-CHIP audio pattern, or of silence
+func (audio *SDLAudio) PatternTick(playing bool, pattern [16]uint8, rate float64) {
+	audio.queue(func(samples []int16) {
+		audio.tone.PatternSamples(samples, playing, pattern, rate)
+	})
+}
+
+// queue : Queue a tick of samples made by fill, keeping a few ticks queued
+// Ticks come from the wall clock and samples are played by the sound card's clock, so a tick is
+// dropped when the queue runs ahead and doubled when it runs dry.
+func (audio *SDLAudio) queue(fill func(samples []int16)) {
+	queued := int(sdl.GetQueuedAudioSize(audio.device)) / 2
+	n := audio.tick
+	switch {
+	case queued > 4*audio.tick:
+		return
+	case queued < audio.tick:
+		n = 2 * audio.tick
+	}
+	if cap(audio.samples) < n {
+		audio.samples = make([]int16, n)
+		audio.data = make([]byte, 2*n)
+	}
+	samples, data := audio.samples[:n], audio.data[:2*n]
+	fill(samples)
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(sample))
+	}
+	sdl.QueueAudio(audio.device, data)
+}
+
+// Close : Close the audio device
+func (audio *SDLAudio) Close() {
+	sdl.CloseAudioDevice(audio.device)
+}
@@ -1,15 +1,27 @@
+//go:build !nosdl
+
 package main
 
 import (
+	"fmt"
+
 	"github.com/veandco/go-sdl2/sdl"
 	"gopkg.in/ini.v1"
 )
 
 type SDLKeyboard struct {
-	keycodeMap       map[uint16]uint8
-	scancodeMap      map[uint16]uint8
-	scancodeReversed map[uint8]uint16
-	specialMap       map[string]uint16
+	keycodeMap     map[uint16]uint8
+	scancodeMap    map[uint16]uint8
+	specialMap     map[string]uint16
+	rewindScancode uint16                    // held to rewind
+	keys           [16]bool                  // keypad held at the start of the frame
+	presses        []uint8                   // keypad presses read at the start of the frame, for Fx0A
+	slotKeys       map[uint16]int            // F1-F9 to quick-save slots 1-9
+	onSlot         func(slot int, save bool) // called on a slot key, saving with shift held
+	onBreak        func()                    // called on the break key, to pause in the debugger
+	onRecord       func()                    // called on the record key, to start or stop recording audio
+	onScreenshot   func()                    // called on the screenshot key
+	onVideo        func()                    // called on the video key, to start or stop recording gameplay
 }
 
 func (keyboard *SDLKeyboard) generateKeymaps() {
@@ -33,6 +45,11 @@ B = C
 F = V
 PAUSE = Space
 QUIT = Escape
+REWIND = Backspace
+BREAK = F12
+RECORD = F10
+SCREENSHOT = F11
+VIDEO = Insert
 `))
 	}
 	check(err)
@@ -76,44 +93,94 @@ QUIT = Escape
 	scancodeMap[uint16(sdl.GetScancodeFromName(keycfg.Section("").Key("B").Value()))] = 0xB
 	scancodeMap[uint16(sdl.GetScancodeFromName(keycfg.Section("").Key("F").Value()))] = 0xF
 	keyboard.scancodeMap = scancodeMap
-	keyboard.scancodeReversed = reverseMap(scancodeMap)
 
 	specialMap := make(map[string]uint16, 2)
 	specialMap["QUIT"] = uint16(sdl.GetKeyFromName(keycfg.Section("").Key("QUIT").Value()))
 	specialMap["PAUSE"] = uint16(sdl.GetKeyFromName(keycfg.Section("").Key("PAUSE").Value()))
+	specialMap["BREAK"] = uint16(sdl.GetKeyFromName(keycfg.Section("").Key("BREAK").MustString("F12")))
+	specialMap["RECORD"] = uint16(sdl.GetKeyFromName(keycfg.Section("").Key("RECORD").MustString("F10")))
+	specialMap["SCREENSHOT"] = uint16(sdl.GetKeyFromName(keycfg.Section("").Key("SCREENSHOT").MustString("F11")))
+	specialMap["VIDEO"] = uint16(sdl.GetKeyFromName(keycfg.Section("").Key("VIDEO").MustString("Insert")))
 	keyboard.specialMap = specialMap
+	keyboard.rewindScancode = uint16(sdl.GetScancodeFromName(keycfg.Section("").Key("REWIND").MustString("Backspace")))
+
+	slotKeys := make(map[uint16]int, 9)
+	for slot := 1; slot <= 9; slot++ {
+		slotKeys[uint16(sdl.GetKeyFromName(fmt.Sprintf("F%d", slot)))] = slot
+	}
+	keyboard.slotKeys = slotKeys
+}
+
+// bindKeys : Also map these SDL key names to CHIP-8 keys, keeping the keys already bound in keys.ini
+func (keyboard *SDLKeyboard) bindKeys(keys map[string]uint8) {
+	for name, key := range keys {
+		keycode := uint16(sdl.GetKeyFromName(name))
+		if _, ok := keyboard.keycodeMap[keycode]; !ok {
+			keyboard.keycodeMap[keycode] = key
+		}
+		scancode := uint16(sdl.GetScancodeFromName(name))
+		if _, ok := keyboard.scancodeMap[scancode]; !ok {
+			keyboard.scancodeMap[scancode] = key
+		}
+	}
 }
 
-func (keyboard *SDLKeyboard) waitForKeyPress() (uint8, bool) {
-	var val uint8
-	loop := true
-	quit := false
-	for loop {
+func (keyboard *SDLKeyboard) WaitForKeyPress() (uint8, bool) {
+	for {
 		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
 			switch t := event.(type) {
 			case *sdl.QuitEvent:
-				quit = true
+				return 0, false
 			case *sdl.KeyboardEvent:
 				if t.Type == 768 {
 					if val, ok := keyboard.keycodeMap[uint16(t.Keysym.Sym)]; ok {
-						return val, quit
+						return val, true
 					}
 				}
 			}
 		}
+		sdl.Delay(1)
 	}
+}
 
-	return val, quit
+// PollKeyPress : Keypad key pressed at the start of this frame, without blocking
+// The events are read by specialKeyPressed at the start of each frame, so the frame loop keeps
+// ticking the timers, rendering and handling the special keys while Fx0A waits, and a waiting
+// Fx0A polls every cycle so it sees each press. Quitting is handled by the loop too, so this is
+// always running.
+func (keyboard *SDLKeyboard) PollKeyPress() (uint8, bool, bool) {
+	if len(keyboard.presses) == 0 {
+		return 0, false, true
+	}
+	key := keyboard.presses[0]
+	keyboard.presses = keyboard.presses[1:]
+	return key, true, true
+}
 
+// SampleKeys : Read the keypad at the start of a frame, so keys don't change mid-frame
+func (keyboard *SDLKeyboard) SampleKeys(frame uint64) {
+	arr := sdl.GetKeyboardState()
+	keyboard.keys = [16]bool{}
+	for scancode, key := range keyboard.scancodeMap {
+		if arr[scancode] == 1 {
+			keyboard.keys[key] = true // several host keys can be bound to one CHIP-8 key
+		}
+	}
 }
 
-func (keyboard *SDLKeyboard) isKeyPressed(key uint8) bool {
+func (keyboard *SDLKeyboard) IsKeyPressed(key uint8) bool {
+	return keyboard.keys[key&0xF]
+}
+
+// rewinding : Whether the rewind key is held down
+func (keyboard *SDLKeyboard) rewinding() bool {
 	arr := sdl.GetKeyboardState()
-	return arr[keyboard.scancodeReversed[key]] == 1
+	return arr[keyboard.rewindScancode] == 1
 }
 
 func (keyboard *SDLKeyboard) specialKeyPressed(paused bool) (bool, bool) {
 	running := true
+	keyboard.presses = keyboard.presses[:0]
 	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
 		switch t := event.(type) {
 		case *sdl.QuitEvent:
@@ -125,6 +192,29 @@ func (keyboard *SDLKeyboard) specialKeyPressed(paused bool) (bool, bool) {
 					paused = !paused
 				case keyboard.specialMap["QUIT"]:
 					running = false
+				case keyboard.specialMap["BREAK"]:
+					if keyboard.onBreak != nil {
+						keyboard.onBreak()
+					}
+				case keyboard.specialMap["RECORD"]:
+					if keyboard.onRecord != nil {
+						keyboard.onRecord()
+					}
+				case keyboard.specialMap["SCREENSHOT"]:
+					if keyboard.onScreenshot != nil {
+						keyboard.onScreenshot()
+					}
+				case keyboard.specialMap["VIDEO"]:
+					if keyboard.onVideo != nil {
+						keyboard.onVideo()
+					}
+				default:
+					if slot, ok := keyboard.slotKeys[uint16(t.Keysym.Sym)]; ok && keyboard.onSlot != nil {
+						keyboard.onSlot(slot, t.Keysym.Mod&sdl.KMOD_SHIFT != 0)
+					}
+					if key, ok := keyboard.keycodeMap[uint16(t.Keysym.Sym)]; ok && t.Repeat == 0 {
+						keyboard.presses = append(keyboard.presses, key)
+					}
 				}
 			}
 		}
@@ -0,0 +1,171 @@
+//go:build !nosdl
+
+package main
+
+import (
+	"log"
+
+	"github.com/jamesmcm/chip8go/chip8"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// runSDL : Run in an SDL window until the program halts or is quit, returns the exit status
+func runSDL(config chip8.Config, rombytes []byte, states stateFiles, window windowOptions, debug bool) int {
+	display := SDLDisplay{}
+	display.init(window.scalingFactor, window.palette)
+
+	keyboard := SDLKeyboard{}
+	keyboard.generateKeymaps()
+	keyboard.bindKeys(window.keys)
+
+	config.Display = &display
+	config.Keyboard = window.movie.keyboard(&keyboard)
+	audio, err := newSDLAudio(window.tone)
+	if err != nil {
+		log.Printf("No sound: %v", err)
+		window.recording.out = &chip8.NullAudio{}
+	} else {
+		window.recording.out = audio
+	}
+	config.Audio = window.recording
+	keyboard.onRecord = window.recording.toggle
+	vm, err := newMachine(config, rombytes, states.load)
+	check(err)
+	window.movie.start(vm)
+	if audio != nil {
+		audio.tick = audio.tone.Rate() / vm.TimerSpeed()
+	}
+	keyboard.onScreenshot = func() {
+		if err := window.screenshots.take(vm, ""); err != nil {
+			log.Print(err)
+		}
+	}
+	keyboard.onVideo = window.video.toggle
+	keyboard.onSlot = func(slot int, save bool) {
+		if !save && window.movie.active() {
+			log.Print("Quick-loading is disabled while an input movie is played or recorded")
+			return
+		}
+		quickSlot(vm, states.slotPath(slot), save)
+	}
+
+	var rewind *chip8.Rewind
+	if window.rewindSeconds > 0 {
+		rewind = chip8.NewRewind(vm, window.rewindSeconds*vm.TimerSpeed())
+	}
+	var session *debugSession
+	if window.debugger || window.gdb != "" || window.dap != nil {
+		session, err = newDebugSession(vm, window.debugger, window.gdb, window.dap)
+		check(err)
+		keyboard.onBreak = session.debugger.Interrupt
+	}
+
+	err = loop(vm, &keyboard, window.maxFrameSkip, rewind, session, func(vm *chip8.Machine) {
+		window.screenshots.atFrame(vm)
+		window.video.capture(vm)
+	})
+	if session != nil {
+		session.close()
+	}
+	switch vm.Halted() {
+	case chip8.HaltSelfJump, chip8.HaltSpinLoop:
+		log.Printf("Program halted (%s) at PC: 0x%x", vm.Halted(), vm.PC())
+		waitForQuit(&keyboard)
+	}
+
+	if err := window.movie.save(); err != nil {
+		log.Print(err)
+	}
+	if states.save != "" {
+		if err := saveStateFile(vm, states.save); err != nil {
+			log.Print(err)
+		}
+	}
+
+	if err := window.recording.stop(); err != nil {
+		log.Print(err)
+	}
+	if err := window.video.stop(); err != nil {
+		log.Print(err)
+	}
+	if audio != nil {
+		audio.Close()
+	}
+	display.Destroy()
+	sdl.Quit()
+
+	return finish(vm, err, debug)
+}
+
+// quickSlot : Quick-save to or quick-load from a slot file, logging the outcome
+func quickSlot(vm *chip8.Machine, path string, save bool) {
+	if save {
+		if err := saveStateFile(vm, path); err != nil {
+			log.Print(err)
+			return
+		}
+		log.Printf("Saved state to %s", path)
+		return
+	}
+	if err := loadStateFile(vm, path); err != nil {
+		log.Print(err)
+		return
+	}
+	vm.Render() // show the loaded screen straight away, even when paused
+	log.Printf("Loaded state from %s", path)
+}
+
+// waitForQuit : Keep showing the final frame until the window is closed or quit is pressed
+func waitForQuit(keyboard *SDLKeyboard) {
+	paused, ok := false, true
+	for ok {
+		sdl.Delay(10)
+		paused, ok = keyboard.specialKeyPressed(paused)
+	}
+}
+
+// loop : Run the machine in 60Hz frames until it halts or is quit, stepping back a frame at a time
+// while the rewind key is held (rewind is nil when disabled). onFrame is called after each tick.
+// Returns the fault that stopped the machine, if any. Breakpoints and breaking faults pause in the
+// debugger, without one (session is nil) breaking faults also stop the machine.
+func loop(vm *chip8.Machine, keyboard *SDLKeyboard, maxFrameSkip int, rewind *chip8.Rewind, session *debugSession, onFrame func(vm *chip8.Machine)) error {
+	scheduler := chip8.NewScheduler(vm, maxFrameSkip)
+	paused, ok := false, true
+
+	// main loop
+	for {
+		paused, ok = keyboard.specialKeyPressed(paused)
+		if !ok || session != nil && !session.poll() {
+			return nil
+		}
+		if paused || session != nil && session.debugger.Paused() {
+			sdl.Delay(10)
+			scheduler.Reset()
+			continue
+		}
+		if rewind != nil && keyboard.rewinding() {
+			if rewind.Back() {
+				vm.Render()
+			}
+			sdl.Delay(uint32(1000 / vm.TimerSpeed()))
+			scheduler.Reset()
+			continue
+		}
+
+		running, err := scheduler.Tick()
+		if rewind != nil {
+			rewind.Record()
+		}
+		onFrame(vm)
+		if session != nil && session.debugger.Paused() {
+			session.stopped(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !running {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jamesmcm/chip8go/chip8"
+)
+
+// stateFiles : Save state options from the command line
+type stateFiles struct {
+	load string // state to start from
+	save string // state written on exit
+	dir  string // directory for quick-save slots, the ROM's directory when empty
+	rom  string // ROM filename, slot files are named after it
+}
+
+// slotPath : File for quick-save slot n, e.g. "roms/Pong.ch8.1.state"
+func (states stateFiles) slotPath(slot int) string {
+	return namedAfterROM(states.dir, states.rom, fmt.Sprintf("%d.state", slot))
+}
+
+// namedAfterROM : File in dir, or the ROM's directory, named after the ROM with a suffix
+func namedAfterROM(dir, rom, suffix string) string {
+	if dir == "" {
+		dir = filepath.Dir(rom)
+	}
+	return filepath.Join(dir, filepath.Base(rom)+"."+suffix)
+}
+
+// newMachine : Create a machine with the ROM loaded, starting from the save state file if given
+func newMachine(config chip8.Config, rombytes []byte, stateFile string) (*chip8.Machine, error) {
+	vm := chip8.New(config)
+	if err := vm.LoadROM(rombytes); err != nil {
+		return nil, err
+	}
+	if stateFile != "" {
+		if err := loadStateFile(vm, stateFile); err != nil {
+			return nil, err
+		}
+	}
+	return vm, nil
+}
+
+// saveStateFile : Write a save state of the machine, replacing the file only once it is complete
+func saveStateFile(vm *chip8.Machine, path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = vm.SaveState(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("saving state to %s: %v", path, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadStateFile : Restore the machine from a save state file
+func loadStateFile(vm *chip8.Machine, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := vm.LoadState(f); err != nil {
+		return fmt.Errorf("loading state from %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+func check(e error) {
+	if e != nil {
+		log.Print(e)
+		os.Exit(exitError)
+	}
+}
+
+func readROM(filename string) []byte {
+	dat, err := ioutil.ReadFile(filename)
+	check(err)
+	return dat
+}
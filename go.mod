@@ -0,0 +1,9 @@
+module github.com/jamesmcm/chip8go
+
+go 1.18
+
+require (
+	github.com/veandco/go-sdl2 v0.4.35
+	github.com/vharitonsky/iniflags v0.0.0-20180513140207-a33cd0b5f3de
+	gopkg.in/ini.v1 v1.67.0
+)